@@ -1,68 +1,213 @@
+// Command raytracer renders scenes described in the YAML format of The Ray
+// Tracer Challenge.
+//
+//	raytracer render scene.yaml -o out.png [--width n] [--height n] [--samples n] [--depth n] [--workers n]
+//	raytracer info scene.yaml
 package main
 
 import (
-	. "github.com/michaelzhao820/raytracer/raytracer"
-	"log"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	. "github.com/michaelzhao820/raytracer/raytracer"
 )
 
+const usage = `usage:
+  raytracer render scene.yaml [-o out.png] [--width n] [--height n] [--samples n] [--depth n] [--workers n]
+  raytracer info scene.yaml
+`
+
+// errUsage is returned for bad command lines, after the problem has been
+// reported, so that main can exit with status 2.
+var errUsage = errors.New("usage")
+
 func main() {
-	world := NewWorld()
-
-	// Light source
-	world.SetLight(&Light{
-		Position:  NewPoint(-10, 10, -10),
-		Intensity: NewColor(1, 1, 1),
-	})
-
-	// Floor
-	floor := NewPlane()
-	floor.GetMaterial().SetColor(1, 0.9, 0.9)
-	floor.GetMaterial().SetSpecular(0)
-
-	// Middle Sphere
-	middle := NewSphere()
-	mtm, _ := TranslationMatrix(-0.5, 1, 0.5)
-	middle.SetTransform(mtm)
-	middle.GetMaterial().SetColor(0.1, 1, 0.5)
-	middle.GetMaterial().SetDiffuse(0.7)
-	middle.GetMaterial().SetSpecular(0.3)
-
-	// Right Sphere
-	right := NewSphere()
-	scl, _ := ScalingMatrix(0.5, 0.5, 0.5)
-	trn, _ := TranslationMatrix(1.5, 0.5, -0.5)
-	rTx, _ := trn.MultiplyMatrices(scl)
-	right.SetTransform(rTx)
-	right.GetMaterial().SetColor(0.5, 1, 0.1)
-	right.GetMaterial().SetDiffuse(0.7)
-	right.GetMaterial().SetSpecular(0.3)
-
-	// Left Sphere
-	left := NewSphere()
-	scl, _ = ScalingMatrix(0.33, 0.33, 0.33)
-	trn, _ = TranslationMatrix(-1.5, 0.33, -0.75)
-	lTx, _ := trn.MultiplyMatrices(scl)
-	left.SetTransform(lTx)
-	left.GetMaterial().SetColor(1, 0.8, 0.1)
-	left.GetMaterial().SetDiffuse(0.7)
-	left.GetMaterial().SetSpecular(0.3)
-
-	// Add objects to world
-	world.AddObject(floor)
-	world.AddObject(middle)
-	world.AddObject(right)
-	world.AddObject(left)
-
-	// Camera
-	camera := NewCamera(800, 400, math.Pi/3)
-	camera.SetTransform(ViewTransform(
-		NewPoint(0, 1.5, -5),
-		NewPoint(0, 1, 0),
-		NewVector(0, 1, 0),
-	))
-
-	if err := camera.Render(*world); err != nil {
-		log.Fatalf("Render failed: %v", err)
+	err := run(os.Args[1:], os.Stdout, os.Stderr)
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+	case errors.Is(err, errUsage):
+		os.Exit(2)
+	case err != nil:
+		fmt.Fprintln(os.Stderr, "raytracer:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return errUsage
+	}
+	switch args[0] {
+	case "render":
+		return render(args[1:], stderr)
+	case "info":
+		return info(args[1:], stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return nil
+	}
+	fmt.Fprintf(stderr, "unknown command %q\n%s", args[0], usage)
+	return errUsage
+}
+
+func render(args []string, stderr io.Writer) error {
+	fs := newFlagSet("render", stderr)
+	output := fs.String("o", "", "output `file`; .png or .ppm (sRGB), .hdr or .pfm (linear) (default: the scene's name with .png)")
+	width := fs.Int("width", 0, "image width in pixels (default: the scene's camera)")
+	height := fs.Int("height", 0, "image height in pixels (default: the scene's camera, or in proportion to --width)")
+	samples := fs.Int("samples", 1, "rays traced per pixel")
+	depth := fs.Int("depth", 4, "how many times a ray may be reflected or refracted")
+	workers := fs.Int("workers", runtime.NumCPU(), "goroutines rendering in parallel")
+	sceneFile, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	scene, err := LoadSceneFile(sceneFile)
+	if err != nil {
+		return err
+	}
+	if scene.Camera == nil {
+		return fmt.Errorf("%s has no camera", sceneFile)
+	}
+
+	camera := scene.Camera
+	if *width > 0 || *height > 0 {
+		camera, err = resizeCamera(camera, *width, *height)
+		if err != nil {
+			return err
+		}
+	}
+	camera.SetSamples(*samples)
+	camera.SetDepth(*depth)
+	camera.SetWorkers(*workers)
+
+	if *output == "" {
+		*output = strings.TrimSuffix(filepath.Base(sceneFile), filepath.Ext(sceneFile)) + ".png"
+	}
+
+	start := time.Now()
+	if err := camera.RenderToFile(*scene.World, *output); err != nil {
+		return err
+	}
+	fmt.Fprintf(stderr, "rendered %gx%g to %s in %v\n", camera.GetHSize(), camera.GetVSize(), *output,
+		time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// resizeCamera returns a copy of camera with a new canvas size. When only one
+// side is given the other keeps the camera's aspect ratio.
+func resizeCamera(camera *Camera, width, height int) (*Camera, error) {
+	hsize, vsize := float64(width), float64(height)
+	switch {
+	case width <= 0:
+		hsize = math.Max(1, math.Round(vsize*camera.GetHSize()/camera.GetVSize()))
+	case height <= 0:
+		vsize = math.Max(1, math.Round(hsize*camera.GetVSize()/camera.GetHSize()))
+	}
+	resized := NewCamera(hsize, vsize, camera.GetFieldOfView())
+	if err := resized.SetTransform(camera.GetTransform()); err != nil {
+		return nil, err
+	}
+	return resized, nil
+}
+
+func info(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("info", stderr)
+	sceneFile, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	scene, err := LoadSceneFile(sceneFile)
+	if err != nil {
+		return err
+	}
+
+	objects := scene.World.GetObjects()
+	shapes := 0
+	for _, o := range objects {
+		shapes += countShapes(o)
+	}
+	fmt.Fprintf(stdout, "objects: %d (%d shapes in all)\n", len(objects), shapes)
+	fmt.Fprintf(stdout, "lights:  %d\n", len(scene.World.GetLights()))
+
+	bounds := scene.World.Bounds()
+	if bounds.IsEmpty() {
+		fmt.Fprintln(stdout, "bounds:  none")
+	} else {
+		fmt.Fprintf(stdout, "bounds:  %s to %s\n", formatPoint(bounds.Min), formatPoint(bounds.Max))
+	}
+
+	if c := scene.Camera; c != nil {
+		fmt.Fprintf(stdout, "camera:  %gx%g, field of view %g\n", c.GetHSize(), c.GetVSize(), c.GetFieldOfView())
+	} else {
+		fmt.Fprintln(stdout, "camera:  none")
+	}
+	return nil
+}
+
+// countShapes counts s and every shape nested inside it.
+func countShapes(s Shape) int {
+	switch s := s.(type) {
+	case *Group:
+		n := 1
+		for _, child := range s.GetChildren() {
+			n += countShapes(child)
+		}
+		return n
+	case *CSG:
+		return 1 + countShapes(s.GetLeft()) + countShapes(s.GetRight())
+	}
+	return 1
+}
+
+func formatPoint(p Tuple) string {
+	return fmt.Sprintf("(%g, %g, %g)", p[X], p[Y], p[Z])
+}
+
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		if name == "render" {
+			fmt.Fprintln(stderr, "\nrender flags:")
+			fs.PrintDefaults()
+		}
+	}
+	return fs
+}
+
+// parseArgs parses the flags in args, which may come before or after the
+// scene file, and returns the scene file.
+func parseArgs(fs *flag.FlagSet, args []string) (string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return "", err
+			}
+			return "", errUsage
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(positional) != 1 {
+		fmt.Fprintf(fs.Output(), "%s needs exactly one scene file\n", fs.Name())
+		fs.Usage()
+		return "", errUsage
 	}
+	return positional[0], nil
 }
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testScene = `
+- add: camera
+  width: 40
+  height: 20
+  field-of-view: 1
+  from: [0, 0, -5]
+  to: [0, 0, 0]
+  up: [0, 1, 0]
+- add: light
+  at: [-10, 10, -10]
+  intensity: [1, 1, 1]
+- add: sphere
+- add: group
+  children:
+    - add: cube
+      transform: [[translate, 3, 0, 0]]
+    - add: sphere
+`
+
+func writeTestScene(t *testing.T) string {
+	filename := filepath.Join(t.TempDir(), "scene.yml")
+	if err := os.WriteFile(filename, []byte(testScene), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestRender(t *testing.T) {
+	scene := writeTestScene(t)
+
+	t.Run("Rendering with flags after the scene file", func(t *testing.T) {
+		output := filepath.Join(t.TempDir(), "out.png")
+		var stderr bytes.Buffer
+		err := run([]string{"render", scene, "-o", output, "--width", "10", "--samples", "2", "--workers", "2"}, &bytes.Buffer{}, &stderr)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v (%s)", err, stderr.String())
+		}
+
+		file, err := os.Open(output)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer file.Close()
+		img, err := png.Decode(file)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		// the height keeps the scene's 2:1 aspect ratio
+		if size := img.Bounds().Size(); size.X != 10 || size.Y != 5 {
+			t.Errorf("Expected a 10x5 image, got %v", size)
+		}
+	})
+
+	t.Run("Bad command lines are usage errors", func(t *testing.T) {
+		for _, args := range [][]string{
+			{},
+			{"draw", scene},
+			{"render"},
+			{"render", scene, scene},
+			{"render", scene, "--samples", "many"},
+		} {
+			err := run(args, &bytes.Buffer{}, &bytes.Buffer{})
+			if !errors.Is(err, errUsage) {
+				t.Errorf("%q: expected a usage error, got %v", args, err)
+			}
+		}
+	})
+
+	t.Run("Scene errors are reported", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "bad.yml")
+		os.WriteFile(filename, []byte("- add: teapot\n"), 0o644)
+		err := run([]string{"render", filename}, &bytes.Buffer{}, &bytes.Buffer{})
+		if err == nil || !strings.Contains(err.Error(), "scene line 1") {
+			t.Errorf("Expected a scene error, got %v", err)
+		}
+	})
+}
+
+func TestInfo(t *testing.T) {
+	var stdout bytes.Buffer
+	if err := run([]string{"info", writeTestScene(t)}, &stdout, &bytes.Buffer{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "objects: 2 (4 shapes in all)\n" +
+		"lights:  1\n" +
+		"bounds:  (-1, -1, -1) to (4, 1, 1)\n" +
+		"camera:  40x20, field of view 1\n"
+	if stdout.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, stdout.String())
+	}
+}
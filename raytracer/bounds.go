@@ -0,0 +1,122 @@
+package raytracer
+
+import "math"
+
+// BoundingBox is an axis-aligned box. An empty box has Min at +Inf and Max at
+// -Inf so that adding any point to it produces a box around that point.
+type BoundingBox struct {
+	Min Tuple
+	Max Tuple
+}
+
+func NewBoundingBox(min, max Tuple) BoundingBox {
+	return BoundingBox{Min: min, Max: max}
+}
+
+func EmptyBoundingBox() BoundingBox {
+	inf := math.Inf(1)
+	return BoundingBox{Min: NewPoint(inf, inf, inf), Max: NewPoint(-inf, -inf, -inf)}
+}
+
+func (b BoundingBox) IsEmpty() bool {
+	return b.Min[X] > b.Max[X] || b.Min[Y] > b.Max[Y] || b.Min[Z] > b.Max[Z]
+}
+
+// IsInfinite reports whether the box is unbounded along any axis, as it is
+// for a plane.
+func (b BoundingBox) IsInfinite() bool {
+	for i := X; i <= Z; i++ {
+		if math.IsInf(b.Min[i], 0) || math.IsInf(b.Max[i], 0) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b BoundingBox) AddPoint(p Tuple) BoundingBox {
+	return BoundingBox{
+		Min: NewPoint(math.Min(b.Min[X], p[X]), math.Min(b.Min[Y], p[Y]), math.Min(b.Min[Z], p[Z])),
+		Max: NewPoint(math.Max(b.Max[X], p[X]), math.Max(b.Max[Y], p[Y]), math.Max(b.Max[Z], p[Z])),
+	}
+}
+
+func (b BoundingBox) Merge(other BoundingBox) BoundingBox {
+	if other.IsEmpty() {
+		return b
+	}
+	return b.AddPoint(other.Min).AddPoint(other.Max)
+}
+
+func (b BoundingBox) ContainsPoint(p Tuple) bool {
+	return b.Min[X] <= p[X] && p[X] <= b.Max[X] &&
+		b.Min[Y] <= p[Y] && p[Y] <= b.Max[Y] &&
+		b.Min[Z] <= p[Z] && p[Z] <= b.Max[Z]
+}
+
+func (b BoundingBox) ContainsBox(other BoundingBox) bool {
+	return b.ContainsPoint(other.Min) && b.ContainsPoint(other.Max)
+}
+
+// Centroid returns the point in the middle of the box.
+func (b BoundingBox) Centroid() Tuple {
+	return NewPoint((b.Min[X]+b.Max[X])/2, (b.Min[Y]+b.Max[Y])/2, (b.Min[Z]+b.Max[Z])/2)
+}
+
+// Transform returns the axis-aligned box around b after it has been
+// transformed by m. Each output axis is built from the translation plus the
+// smaller and larger of every scaled input extent (Arvo's method), which
+// also keeps infinite extents infinite instead of turning them into NaN.
+func (b BoundingBox) Transform(m Matrix) BoundingBox {
+	if b.IsEmpty() {
+		return b
+	}
+
+	min := NewPoint(0, 0, 0)
+	max := NewPoint(0, 0, 0)
+	for i := X; i <= Z; i++ {
+		t, _ := m.Get(int(i), 3)
+		min[i], max[i] = t, t
+		for j := X; j <= Z; j++ {
+			v, _ := m.Get(int(i), int(j))
+			if v == 0 {
+				continue
+			}
+			lo, hi := v*b.Min[j], v*b.Max[j]
+			min[i] += math.Min(lo, hi)
+			max[i] += math.Max(lo, hi)
+		}
+	}
+	return BoundingBox{Min: min, Max: max}
+}
+
+// Intersects reports whether the ray passes through the box. It is the slab
+// test from Cube, except that a ray parallel to a slab is decided by whether
+// its origin lies between the two planes, so rays starting exactly on a face
+// are not lost to 0 * Inf.
+func (b BoundingBox) Intersects(r Ray) bool {
+	tmin, tmax := math.Inf(-1), math.Inf(1)
+	for i := X; i <= Z; i++ {
+		o, d := r.origin[i], r.direction[i]
+		if math.Abs(d) < EPSILON {
+			if o < b.Min[i] || o > b.Max[i] {
+				return false
+			}
+			continue
+		}
+
+		t0 := (b.Min[i] - o) / d
+		t1 := (b.Max[i] - o) / d
+		if t0 > t1 {
+			t0, t1 = t1, t0
+		}
+		tmin = math.Max(tmin, t0)
+		tmax = math.Min(tmax, t1)
+	}
+	return tmin <= tmax && tmax >= 0
+}
+
+// ParentSpaceBounds returns the bounds of s in the space of its parent, or in
+// world space when it has none.
+func ParentSpaceBounds(s Shape) BoundingBox {
+	return s.Bounds().Transform(s.GetTransformMatrix())
+}
@@ -0,0 +1,163 @@
+package raytracer
+
+import (
+	"sort"
+	"sync"
+)
+
+// maxLeafShapes is the number of shapes below which a BVH node is not split
+// any further.
+const maxLeafShapes = 4
+
+// bvh is a bounding volume hierarchy over a list of shapes, all expressed in
+// the same space. Shapes with infinite bounds, such as planes, cannot be
+// placed in the tree usefully and are kept in a separate list that every ray
+// is tested against.
+type bvh struct {
+	root      *bvhNode
+	unbounded []Shape
+}
+
+// lazyBVH holds a hierarchy that is built the first time it is needed. The
+// build is guarded by a sync.Once, so rays traced from several goroutines can
+// share it; discarding the hierarchy means replacing the lazyBVH.
+type lazyBVH struct {
+	once sync.Once
+	bvh  *bvh
+}
+
+// builtBVH returns a lazyBVH whose hierarchy over shapes is already built.
+func builtBVH(shapes []Shape) *lazyBVH {
+	l := &lazyBVH{}
+	l.get(shapes)
+	return l
+}
+
+// get returns the hierarchy, building it over shapes on the first call.
+func (l *lazyBVH) get(shapes []Shape) *bvh {
+	l.once.Do(func() {
+		l.bvh = newBVH(shapes)
+	})
+	return l.bvh
+}
+
+type bvhNode struct {
+	bounds BoundingBox
+	shapes []Shape
+	left   *bvhNode
+	right  *bvhNode
+}
+
+type bvhEntry struct {
+	shape    Shape
+	bounds   BoundingBox
+	centroid Tuple
+}
+
+// newBVH builds a hierarchy over shapes. Groups among them, including those
+// nested in CSGs, build their own hierarchies first, so that nothing is built
+// lazily while rays are being traced.
+func newBVH(shapes []Shape) *bvh {
+	b := &bvh{}
+	var entries []bvhEntry
+	for _, s := range shapes {
+		buildAcceleration(s)
+		bounds := ParentSpaceBounds(s)
+		if bounds.IsInfinite() {
+			b.unbounded = append(b.unbounded, s)
+			continue
+		}
+		entries = append(entries, bvhEntry{shape: s, bounds: bounds, centroid: bounds.Centroid()})
+	}
+	if len(entries) > 0 {
+		b.root = buildBVHNode(entries)
+	}
+	return b
+}
+
+// buildBVHNode splits entries at the median centroid along the axis where
+// the centroids are spread out the most.
+func buildBVHNode(entries []bvhEntry) *bvhNode {
+	node := &bvhNode{bounds: EmptyBoundingBox()}
+	centroids := EmptyBoundingBox()
+	for _, e := range entries {
+		node.bounds = node.bounds.Merge(e.bounds)
+		centroids = centroids.AddPoint(e.centroid)
+	}
+
+	if len(entries) <= maxLeafShapes {
+		for _, e := range entries {
+			node.shapes = append(node.shapes, e.shape)
+		}
+		return node
+	}
+
+	axis := X
+	extent := centroids.Max[X] - centroids.Min[X]
+	for _, i := range []PositionIndex{Y, Z} {
+		if e := centroids.Max[i] - centroids.Min[i]; e > extent {
+			axis, extent = i, e
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].centroid[axis] < entries[j].centroid[axis]
+	})
+	mid := len(entries) / 2
+	node.left = buildBVHNode(entries[:mid])
+	node.right = buildBVHNode(entries[mid:])
+	return node
+}
+
+// intersect appends the intersections of r with every shape whose bounds it
+// passes through. The result is not sorted.
+func (b *bvh) intersect(r Ray, xs []Intersection) []Intersection {
+	for _, s := range b.unbounded {
+		xs = append(xs, s.Intersect(r)...)
+	}
+	if b.root != nil {
+		xs = b.root.intersect(r, xs)
+	}
+	return xs
+}
+
+func (n *bvhNode) intersect(r Ray, xs []Intersection) []Intersection {
+	if !n.bounds.Intersects(r) {
+		return xs
+	}
+	for _, s := range n.shapes {
+		xs = append(xs, s.Intersect(r)...)
+	}
+	if n.left != nil {
+		xs = n.left.intersect(r, xs)
+	}
+	if n.right != nil {
+		xs = n.right.intersect(r, xs)
+	}
+	return xs
+}
+
+// bounds returns the box around everything in the hierarchy.
+func (b *bvh) bounds() BoundingBox {
+	box := EmptyBoundingBox()
+	if b.root != nil {
+		box = b.root.bounds
+	}
+	for _, s := range b.unbounded {
+		box = box.Merge(ParentSpaceBounds(s))
+	}
+	return box
+}
+
+// buildAcceleration builds the hierarchy of every group under s that does
+// not have one yet. It goes through each group's lazyBVH, so worlds that
+// share groups can be prepared for rendering at the same time.
+func buildAcceleration(s Shape) {
+	switch shape := s.(type) {
+	case *Group:
+		shape.accelerator()
+	case *CSG:
+		buildAcceleration(shape.left)
+		buildAcceleration(shape.right)
+	}
+}
@@ -1,21 +1,77 @@
 package raytracer
 
-import "math"
+import (
+	"math"
+	"math/rand/v2"
+	"runtime"
+	"sync"
+)
+
+// Projection is how a Camera maps the canvas onto rays.
+type Projection int
+
+const (
+	// PerspectiveProjection is a pinhole (or thin-lens) camera whose field of
+	// view spans the longer side of the canvas.
+	PerspectiveProjection Projection = iota
+	// OrthographicProjection fires parallel rays from a rectangle of width
+	// SetViewWidth, so objects do not shrink with distance.
+	OrthographicProjection
+	// FisheyeProjection is an equidistant fisheye: the angle of a ray from the
+	// view direction grows with its distance from the centre of the canvas,
+	// reaching half the field of view at the edge of the largest circle that
+	// fits on the canvas. Pixels outside that circle are black.
+	FisheyeProjection
+	// EquirectangularProjection maps longitude across the canvas and latitude
+	// down it, covering every direction around the camera for a 360 degree
+	// panorama. A 2:1 canvas keeps the pixels square.
+	EquirectangularProjection
+)
+
+// renderTileSize is the width and height, in pixels, of the square tiles
+// that Render hands out to its workers.
+const renderTileSize = 16
 
 type Camera struct {
-	hsize       float64
-	vsize       float64
-	fieldOfView float64
-	transform   Matrix
-	pixelSize   float64
-	halfWidth   float64
-	halfHeight  float64
+	hsize         float64
+	vsize         float64
+	fieldOfView   float64
+	transform     Matrix
+	inverse       Mat4
+	pixelSize     float64
+	halfWidth     float64
+	halfHeight    float64
+	workers       int
+	samples       int
+	filter        Filter
+	seed          uint64
+	threshold     float64
+	maxDepth      int
+	aperture      float64
+	focalDistance float64
+	projection    Projection
+	viewWidth     float64
+	depth         int
 }
 
 func NewCamera(hsize, vsize, fieldOfView float64) *Camera {
-	return &Camera{hsize, vsize, fieldOfView, IdentityMatrix(),
-		calculatePixelSize(hsize, vsize, fieldOfView), computeHalfWidth(hsize, vsize, fieldOfView),
-		computeHalfHeight(hsize, vsize, fieldOfView)}
+	return &Camera{
+		hsize:         hsize,
+		vsize:         vsize,
+		fieldOfView:   fieldOfView,
+		transform:     IdentityMatrix(),
+		inverse:       Identity4(),
+		pixelSize:     calculatePixelSize(hsize, vsize, fieldOfView),
+		halfWidth:     computeHalfWidth(hsize, vsize, fieldOfView),
+		halfHeight:    computeHalfHeight(hsize, vsize, fieldOfView),
+		workers:       runtime.NumCPU(),
+		samples:       1,
+		filter:        NewBoxFilter(),
+		focalDistance: 1,
+		projection:    PerspectiveProjection,
+		viewWidth:     2,
+		depth:         4,
+	}
 }
 
 func computeHalfHeight(hsize float64, vsize float64, fieldOfView float64) float64 {
@@ -67,41 +123,443 @@ func calculatePixelSize(hsize float64, vsize float64, fieldOfView float64) float
 //
 // Finally, the ray is created with the world-space origin and a direction pointing
 // toward the world-space pixel, normalized to ensure a unit-length direction vector.
+//
+// Other projections replace the canvas at z = -1 (see Projection), but all of
+// them build their ray in camera space and move it into world space the same
+// way. The result is false when no ray passes through the pixel.
+func (c *Camera) rayForPixel(px, py float64) (Ray, bool) {
+	return c.rayThrough(px+0.5, py+0.5, 0.5, 0.5)
+}
+
+// rayThrough is rayForPixel for an arbitrary point (x, y) on the canvas,
+// measured in pixels from its upper-left corner rather than by pixel index.
+// (lensU, lensV) picks a point on the lens for a perspective camera with an
+// aperture and is ignored otherwise.
+func (c *Camera) rayThrough(x, y, lensU, lensV float64) (Ray, bool) {
+	switch c.projection {
+	case OrthographicProjection:
+		return c.orthographicRay(x, y), true
+	case FisheyeProjection:
+		return c.fisheyeRay(x, y)
+	case EquirectangularProjection:
+		return c.equirectangularRay(x, y), true
+	}
+	return c.perspectiveRay(x, y, lensU, lensV), true
+}
 
-func (c *Camera) rayForPixel(px, py float64) Ray {
-	xoffset := (px + 0.5) * c.pixelSize
-	yoffset := (py + 0.5) * c.pixelSize
+// toWorld moves a ray from camera space into world space.
+func (c *Camera) toWorld(origin, direction Vec4) Ray {
+	return Ray{
+		origin:    c.inverse.MultiplyWithVec(origin),
+		direction: c.inverse.MultiplyWithVec(direction).Normalize(),
+	}
+}
+
+// perspectiveRay aims a ray from the camera through (x, y) on the canvas at
+// z = -1.
+//
+// With an aperture the camera is a thin lens rather than a pinhole: the ray
+// starts at the point (lensU, lensV) of the unit square mapped onto the lens
+// disk, and is aimed at the point where the pinhole ray crosses the focal
+// plane, so only objects at the focal distance are sharp. A lens point of
+// (0.5, 0.5) is the centre of the lens and gives the pinhole ray.
+func (c *Camera) perspectiveRay(x, y, lensU, lensV float64) Ray {
+	xoffset := x * c.pixelSize
+	yoffset := y * c.pixelSize
 
 	cameraX := c.halfWidth - xoffset
 	cameraY := c.halfHeight - yoffset
 
-	pointTM, _ := c.transform.Inverse()
+	if c.aperture == 0 {
+		pixel := c.inverse.MultiplyWithVec(Point4(cameraX, cameraY, -1))
+		origin := c.inverse.MultiplyWithVec(Point4(0, 0, 0))
+		return Ray{origin: origin, direction: pixel.Sub(origin).Normalize()}
+	}
+
+	lensX, lensY := concentricDisk(lensU, lensV)
+	focus := c.inverse.MultiplyWithVec(Point4(cameraX*c.focalDistance, cameraY*c.focalDistance, -c.focalDistance))
+	origin := c.inverse.MultiplyWithVec(Point4(lensX*c.aperture, lensY*c.aperture, 0))
+	return Ray{origin: origin, direction: focus.Sub(origin).Normalize()}
+}
 
-	pixel, _ := pointTM.MultiplyWithTuple(NewPoint(cameraX, cameraY, -1))
-	origin, _ := pointTM.MultiplyWithTuple(NewPoint(0, 0, 0))
+// orthographicRay fires a ray straight down -z from (x, y) on a canvas that
+// is viewWidth wide in camera space.
+func (c *Camera) orthographicRay(x, y float64) Ray {
+	pixelSize := c.viewWidth / c.hsize
+	cameraX := c.viewWidth/2 - x*pixelSize
+	cameraY := pixelSize*c.vsize/2 - y*pixelSize
+	return c.toWorld(Point4(cameraX, cameraY, 0), Vector4(0, 0, -1))
+}
 
-	direction, _ := pixel.Subtract(origin)
-	direction, _ = direction.Normalize()
+// fisheyeRay turns the distance of (x, y) from the centre of the canvas into
+// the angle of the ray from -z, and its direction around the centre into the
+// direction of the ray around -z.
+func (c *Camera) fisheyeRay(x, y float64) (Ray, bool) {
+	radius := math.Min(c.hsize, c.vsize) / 2
+	nx := (c.hsize/2 - x) / radius
+	ny := (c.vsize/2 - y) / radius
+	r := math.Hypot(nx, ny)
+	if r > 1 {
+		return Ray{}, false
+	}
 
-	return NewRay(origin, direction)
+	theta := r * c.fieldOfView / 2
+	phi := math.Atan2(ny, nx)
+	direction := Vector4(math.Sin(theta)*math.Cos(phi), math.Sin(theta)*math.Sin(phi), -math.Cos(theta))
+	return c.toWorld(Point4(0, 0, 0), direction), true
 }
 
-func (c *Camera) Render(w World) error {
+// equirectangularRay treats x as longitude, a full turn across the canvas,
+// and y as latitude, from straight up at the top to straight down at the
+// bottom. The centre of the canvas looks down -z.
+func (c *Camera) equirectangularRay(x, y float64) Ray {
+	longitude := (0.5 - x/c.hsize) * 2 * math.Pi
+	latitude := (0.5 - y/c.vsize) * math.Pi
+	direction := Vector4(
+		math.Cos(latitude)*math.Sin(longitude),
+		math.Sin(latitude),
+		-math.Cos(latitude)*math.Cos(longitude),
+	)
+	return c.toWorld(Point4(0, 0, 0), direction)
+}
+
+// concentricDisk maps a point in the unit square onto the unit disk,
+// keeping points that are evenly spread over the square evenly spread over
+// the disk (Shirley and Chiu's concentric mapping).
+func concentricDisk(u, v float64) (float64, float64) {
+	a, b := 2*u-1, 2*v-1
+	if a == 0 && b == 0 {
+		return 0, 0
+	}
+
+	var r, theta float64
+	if math.Abs(a) > math.Abs(b) {
+		r, theta = a, math.Pi/4*(b/a)
+	} else {
+		r, theta = b, math.Pi/2-math.Pi/4*(a/b)
+	}
+	return r * math.Cos(theta), r * math.Sin(theta)
+}
 
+// traceAt returns the colour seen through the point (x, y) on the canvas.
+// rng picks the point on the lens, when there is an aperture, and the
+// samples of any jittered area light.
+func (c *Camera) traceAt(w *World, rng *rand.Rand, x, y float64) Color {
+	lensU, lensV := 0.5, 0.5
+	if c.aperture > 0 {
+		lensU, lensV = rng.Float64(), rng.Float64()
+	}
+	ray, ok := c.rayThrough(x, y, lensU, lensV)
+	if !ok {
+		return NewColor(0, 0, 0)
+	}
+	return w.colorAt(ray, c.depth, rng)
+}
+
+// Render traces every pixel of the image and returns the result. The canvas
+// is split into tiles that a pool of workers pull from a channel; each pixel
+// is written by exactly one worker, so the canvas needs no locking and the
+// result is the same as rendering the pixels one after another.
+//
+// In adaptive mode (see SetAdaptive) a second pass re-renders the pixels that
+// stand out from their neighbours. It reads the first image and writes a new
+// one, so workers never see a half-refined neighbourhood.
+func (c *Camera) Render(w World) Canvas {
 	image := NewCanvas(int(c.hsize), int(c.vsize))
 
-	for y := 0; y < int(c.vsize); y++ {
-		for x := 0; x < int(c.hsize); x++ {
-			ray := c.rayForPixel(float64(x), float64(y))
-			color := w.ColorAt(ray, 4)
-			image.WritePixel(x, y, color)
+	// build the BVH up front so workers never build it concurrently
+	w.BuildBVH()
+
+	c.renderTiles(func(x, y int) {
+		image.WritePixel(x, y, c.renderPixel(&w, x, y))
+	})
+	if c.maxDepth == 0 {
+		return image
+	}
+
+	refined := NewCanvas(int(c.hsize), int(c.vsize))
+	c.renderTiles(func(x, y int) {
+		color := image.PixelAt(x, y)
+		if c.needsRefinement(&image, x, y) {
+			color = c.refinePixel(&w, x, y)
+		}
+		refined.WritePixel(x, y, color)
+	})
+	return refined
+}
+
+// renderTiles calls pixel for every pixel of the image, handing the image out
+// to the camera's workers a tile at a time.
+func (c *Camera) renderTiles(pixel func(x, y int)) {
+	tiles := make(chan [2]int)
+	var wg sync.WaitGroup
+	for i := 0; i < c.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for tile := range tiles {
+				c.renderTile(tile[0], tile[1], pixel)
+			}
+		}()
+	}
+
+	for y := 0; y < int(c.vsize); y += renderTileSize {
+		for x := 0; x < int(c.hsize); x += renderTileSize {
+			tiles <- [2]int{x, y}
 		}
 	}
-	err := image.CanvasToPPM("scene.ppm")
-	return err
+	close(tiles)
+	wg.Wait()
 }
 
-func (c *Camera) SetTransform(transform Matrix) {
-	c.transform = transform
+// RenderToFile renders the world and saves the image to filename, in the
+// format given by its extension (see Canvas.Save).
+func (c *Camera) RenderToFile(w World, filename string) error {
+	image := c.Render(w)
+	return image.Save(filename)
+}
 
+func (c *Camera) renderTile(x0, y0 int, pixel func(x, y int)) {
+	for y := y0; y < y0+renderTileSize && y < int(c.vsize); y++ {
+		for x := x0; x < x0+renderTileSize && x < int(c.hsize); x++ {
+			pixel(x, y)
+		}
+	}
+}
+
+// renderPixel shoots one ray through the centre of the pixel, or, when the
+// camera takes several samples, spreads them over the filter's support in a
+// grid of strata with one jittered sample in each and combines them with the
+// filter's weights.
+func (c *Camera) renderPixel(w *World, x, y int) Color {
+	rng := c.pixelRNG(x, y)
+	if c.samples == 1 && c.aperture == 0 {
+		return c.traceAt(w, rng, float64(x)+0.5, float64(y)+0.5)
+	}
+
+	radius := c.filter.Radius()
+	cols, rows := strata(c.samples)
+
+	sum, plain := NewColor(0, 0, 0), NewColor(0, 0, 0)
+	total := 0.0
+	for i := 0; i < c.samples; i++ {
+		sx := (float64(i%cols) + rng.Float64()) / float64(cols)
+		sy := (float64(i/cols) + rng.Float64()) / float64(rows)
+		dx, dy := (2*sx-1)*radius, (2*sy-1)*radius
+
+		weight := c.filter.Weight(dx, dy)
+		color := c.traceAt(w, rng, float64(x)+0.5+dx, float64(y)+0.5+dy)
+		sum = sum.AddColor(color.MultiplyByScalar(weight))
+		plain = plain.AddColor(color)
+		total += weight
+	}
+	if total == 0 {
+		// a filter that gives every sample zero weight would leave the pixel
+		// black, so fall back to a plain average
+		return plain.MultiplyByScalar(1 / float64(c.samples))
+	}
+	return sum.MultiplyByScalar(1 / total)
+}
+
+// strata splits n samples into a cols by rows grid with exactly one sample in
+// every cell, as close to square as n allows. A prime n is stratified along
+// x only.
+func strata(n int) (cols, rows int) {
+	rows = int(math.Sqrt(float64(n)))
+	for n%rows != 0 {
+		rows--
+	}
+	return n / rows, rows
+}
+
+// pixelRNG returns a random number generator for pixel (x, y). It depends
+// only on the camera's seed and the pixel, never on which worker renders it
+// or when, so a render is reproducible however many workers it uses.
+func (c *Camera) pixelRNG(x, y int) *rand.Rand {
+	return rand.New(rand.NewPCG(c.seed, uint64(y)*uint64(c.hsize)+uint64(x)))
+}
+
+// needsRefinement reports whether pixel (x, y) differs from any of the four
+// pixels next to it by more than the adaptive threshold.
+func (c *Camera) needsRefinement(image *Canvas, x, y int) bool {
+	color := image.PixelAt(x, y)
+	for _, n := range [4][2]int{{x - 1, y}, {x + 1, y}, {x, y - 1}, {x, y + 1}} {
+		if n[0] < 0 || n[0] >= image.width || n[1] < 0 || n[1] >= image.Height {
+			continue
+		}
+		if colorContrast(color, image.PixelAt(n[0], n[1])) > c.threshold {
+			return true
+		}
+	}
+	return false
+}
+
+// refinePixel traces the four corners of pixel (x, y) and subdivides it
+// wherever they disagree.
+func (c *Camera) refinePixel(w *World, x, y int) Color {
+	rng := c.pixelRNG(x, y)
+	fx, fy := float64(x), float64(y)
+	corners := [4]Color{
+		c.traceAt(w, rng, fx, fy),
+		c.traceAt(w, rng, fx+1, fy),
+		c.traceAt(w, rng, fx, fy+1),
+		c.traceAt(w, rng, fx+1, fy+1),
+	}
+	return c.adaptiveSample(w, rng, fx, fy, 1, corners, c.maxDepth)
+}
+
+// adaptiveSample returns the colour of the square of side size whose upper
+// left corner is at (x, y) on the canvas, given the colours at its corners in
+// the order top-left, top-right, bottom-left, bottom-right. While the corners
+// differ by more than the threshold and depth remains, the square is split
+// into four, sharing the corners already traced.
+func (c *Camera) adaptiveSample(w *World, rng *rand.Rand, x, y, size float64, corners [4]Color, depth int) Color {
+	contrast := 0.0
+	for i := 0; i < 4; i++ {
+		for j := i + 1; j < 4; j++ {
+			contrast = math.Max(contrast, colorContrast(corners[i], corners[j]))
+		}
+	}
+	if depth == 0 || contrast <= c.threshold {
+		return corners[0].AddColor(corners[1]).AddColor(corners[2]).AddColor(corners[3]).MultiplyByScalar(0.25)
+	}
+
+	half := size / 2
+	top := c.traceAt(w, rng, x+half, y)
+	left := c.traceAt(w, rng, x, y+half)
+	centre := c.traceAt(w, rng, x+half, y+half)
+	right := c.traceAt(w, rng, x+size, y+half)
+	bottom := c.traceAt(w, rng, x+half, y+size)
+
+	return c.adaptiveSample(w, rng, x, y, half, [4]Color{corners[0], top, left, centre}, depth-1).
+		AddColor(c.adaptiveSample(w, rng, x+half, y, half, [4]Color{top, corners[1], centre, right}, depth-1)).
+		AddColor(c.adaptiveSample(w, rng, x, y+half, half, [4]Color{left, centre, corners[2], bottom}, depth-1)).
+		AddColor(c.adaptiveSample(w, rng, x+half, y+half, half, [4]Color{centre, right, bottom, corners[3]}, depth-1)).
+		MultiplyByScalar(0.25)
+}
+
+// colorContrast is the largest difference between a and b in any channel.
+func colorContrast(a, b Color) float64 {
+	return math.Max(math.Abs(a[R]-b[R]),
+		math.Max(math.Abs(a[G]-b[G]), math.Abs(a[B]-b[B])))
+}
+
+func (c *Camera) GetHSize() float64 {
+	return c.hsize
+}
+
+func (c *Camera) GetVSize() float64 {
+	return c.vsize
+}
+
+func (c *Camera) GetFieldOfView() float64 {
+	return c.fieldOfView
+}
+
+func (c *Camera) GetTransform() Matrix {
+	return c.transform
+}
+
+// SetAdaptive turns on adaptive supersampling. After a first pass with the
+// camera's usual sampling, every pixel that differs from a neighbour by more
+// than threshold in any channel is re-rendered from rays through its corners,
+// splitting it into quarters while their colours still differ by more than
+// threshold, at most maxDepth times. A maxDepth of 0 turns it off.
+func (c *Camera) SetAdaptive(threshold float64, maxDepth int) {
+	if maxDepth < 0 {
+		maxDepth = 0
+	}
+	c.threshold, c.maxDepth = threshold, maxDepth
+}
+
+// SetAperture sets the radius of the camera's lens. The default of 0 is a
+// pinhole camera with everything in focus; anything larger blurs objects
+// away from the focal distance, and the more samples per pixel (SetSamples)
+// the smoother the blur.
+func (c *Camera) SetAperture(radius float64) {
+	c.aperture = math.Max(0, radius)
+}
+
+// SetFocalDistance sets how far in front of the camera objects are in
+// sharp focus when it has an aperture. It defaults to 1; distances of 0 or
+// less, which would put the focal plane at or behind the lens, are treated
+// as 1.
+func (c *Camera) SetFocalDistance(d float64) {
+	if d <= 0 {
+		d = 1
+	}
+	c.focalDistance = d
+}
+
+// SetProjection sets how the camera maps the canvas onto rays. It defaults
+// to PerspectiveProjection. Every projection is oriented by the camera's
+// transform.
+func (c *Camera) SetProjection(p Projection) {
+	c.projection = p
+}
+
+// SetViewWidth sets how wide, in world units, the canvas of an orthographic
+// camera is. It defaults to 2.
+func (c *Camera) SetViewWidth(width float64) {
+	c.viewWidth = width
+}
+
+// SetSamples sets how many rays are traced for each pixel. The default of 1
+// traces a single ray through the pixel's centre; values below 1 are treated
+// as 1.
+func (c *Camera) SetSamples(n int) {
+	if n < 1 {
+		n = 1
+	}
+	c.samples = n
+}
+
+// SetFilter sets the reconstruction filter used when there is more than one
+// sample per pixel. It defaults to a BoxFilter; nil is treated as a
+// BoxFilter.
+func (c *Camera) SetFilter(f Filter) {
+	if f == nil {
+		f = NewBoxFilter()
+	}
+	c.filter = f
+}
+
+// SetSeed sets the seed for the sub-pixel jitter. Renders with the same seed
+// are identical.
+func (c *Camera) SetSeed(seed uint64) {
+	c.seed = seed
+}
+
+// SetDepth sets how many times a ray may be reflected or refracted. It
+// defaults to 4; values below 0 are treated as 0, which turns reflection and
+// refraction off.
+func (c *Camera) SetDepth(n int) {
+	if n < 0 {
+		n = 0
+	}
+	c.depth = n
+}
+
+// SetWorkers sets how many goroutines Render uses. It defaults to the number
+// of CPUs; values below 1 are treated as 1.
+func (c *Camera) SetWorkers(n int) {
+	if n < 1 {
+		n = 1
+	}
+	c.workers = n
+}
+
+// SetTransform sets the view transform and caches its inverse. It returns an
+// error, leaving the camera unchanged, when the transform cannot be inverted.
+func (c *Camera) SetTransform(transform Matrix) error {
+	m, err := transform.Mat4()
+	if err != nil {
+		return err
+	}
+	inv, err := m.Inverse()
+	if err != nil {
+		return err
+	}
+	c.transform, c.inverse = transform, inv
+	return nil
 }
@@ -0,0 +1,343 @@
+package raytracer
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParallelRender(t *testing.T) {
+	t.Run("Rendering with many workers matches a single worker", func(t *testing.T) {
+		w := NewWorld()
+		w.DefaultWorld()
+		floor := NewPlane()
+		tm, _ := TranslationMatrix(0, -1, 0)
+		floor.SetTransform(tm)
+		floor.GetMaterial().SetReflective(0.5)
+		w.AddObject(floor)
+
+		// 37x21 does not divide into whole tiles
+		c := NewCamera(37, 21, math.Pi/2)
+		c.SetTransform(ViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0)))
+
+		c.SetWorkers(1)
+		serial := c.Render(*w)
+		c.SetWorkers(8)
+		parallel := c.Render(*w)
+
+		for y := 0; y < 21; y++ {
+			for x := 0; x < 37; x++ {
+				if !serial.pixels[y][x].Equals(parallel.pixels[y][x]) {
+					t.Fatalf("Pixel (%d, %d): serial %v, parallel %v", x, y, serial.pixels[y][x], parallel.pixels[y][x])
+				}
+			}
+		}
+	})
+
+	t.Run("The number of workers is at least one", func(t *testing.T) {
+		c := NewCamera(10, 10, math.Pi/2)
+		if c.workers < 1 {
+			t.Errorf("Expected a default of at least one worker, got %d", c.workers)
+		}
+		c.SetWorkers(0)
+		if c.workers != 1 {
+			t.Errorf("Expected SetWorkers(0) to use 1 worker, got %d", c.workers)
+		}
+	})
+}
+
+func TestSupersampling(t *testing.T) {
+	newScene := func() (*World, *Camera) {
+		w := NewWorld()
+		w.DefaultWorld()
+		c := NewCamera(21, 15, math.Pi/2)
+		c.SetTransform(ViewTransform(NewPoint(0, 0, -3), NewPoint(0, 0, 0), NewVector(0, 1, 0)))
+		c.SetSamples(5)
+		c.SetFilter(NewTentFilter(1))
+		return w, c
+	}
+
+	t.Run("A supersampled render does not depend on the number of workers", func(t *testing.T) {
+		w, c := newScene()
+		c.SetSeed(7)
+		c.SetWorkers(1)
+		serial := c.Render(*w)
+		c.SetWorkers(8)
+		parallel := c.Render(*w)
+
+		for y := 0; y < 15; y++ {
+			for x := 0; x < 21; x++ {
+				if !serial.pixels[y][x].Equals(parallel.pixels[y][x]) {
+					t.Fatalf("Pixel (%d, %d): serial %v, parallel %v", x, y, serial.pixels[y][x], parallel.pixels[y][x])
+				}
+			}
+		}
+	})
+
+	t.Run("The seed changes the jitter", func(t *testing.T) {
+		w, c := newScene()
+		c.SetSeed(1)
+		first := c.Render(*w)
+		c.SetSeed(2)
+		second := c.Render(*w)
+
+		for y := 0; y < 15; y++ {
+			for x := 0; x < 21; x++ {
+				if !first.pixels[y][x].Equals(second.pixels[y][x]) {
+					return
+				}
+			}
+		}
+		t.Errorf("Expected different seeds to give different images")
+	})
+
+	t.Run("The number of samples is at least one", func(t *testing.T) {
+		c := NewCamera(10, 10, math.Pi/2)
+		if c.samples != 1 {
+			t.Errorf("Expected a default of one sample, got %d", c.samples)
+		}
+		c.SetSamples(-3)
+		if c.samples != 1 {
+			t.Errorf("Expected SetSamples(-3) to use 1 sample, got %d", c.samples)
+		}
+	})
+}
+
+// zeroFilter gives every sample zero weight.
+type zeroFilter struct{}
+
+func (zeroFilter) Radius() float64 {
+	return 0.5
+}
+
+func (zeroFilter) Weight(dx, dy float64) float64 {
+	return 0
+}
+
+func TestFilterFallbacks(t *testing.T) {
+	w := NewWorld()
+	w.DefaultWorld()
+	c := NewCamera(11, 11, math.Pi/2)
+	c.SetTransform(ViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0)))
+	c.SetSamples(4)
+
+	t.Run("A filter that weights every sample zero gives a plain average", func(t *testing.T) {
+		c.SetFilter(NewBoxFilter())
+		expected := c.renderPixel(w, 5, 5)
+		c.SetFilter(zeroFilter{})
+		if got := c.renderPixel(w, 5, 5); !got.Equals(expected) {
+			t.Errorf("Expected %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("A nil filter is treated as a box filter", func(t *testing.T) {
+		c.SetFilter(nil)
+		if _, ok := c.filter.(BoxFilter); !ok {
+			t.Errorf("Expected a BoxFilter, got %T", c.filter)
+		}
+	})
+}
+
+// recordingFilter is a box filter that remembers where it was sampled.
+type recordingFilter struct {
+	offsets [][2]float64
+}
+
+func (f *recordingFilter) Radius() float64 {
+	return 0.5
+}
+
+func (f *recordingFilter) Weight(dx, dy float64) float64 {
+	f.offsets = append(f.offsets, [2]float64{dx, dy})
+	return 1
+}
+
+func TestSampleStrata(t *testing.T) {
+	t.Run("Every stratum of the pixel gets exactly one sample", func(t *testing.T) {
+		w := NewWorld()
+		for _, n := range []int{2, 3, 4, 5, 6, 12} {
+			filter := &recordingFilter{}
+			c := NewCamera(1, 1, math.Pi/2)
+			c.SetSamples(n)
+			c.SetFilter(filter)
+			c.renderPixel(w, 0, 0)
+
+			cols, rows := strata(n)
+			if cols*rows != n {
+				t.Fatalf("%d samples: %dx%d strata do not hold one sample each", n, cols, rows)
+			}
+			hits := map[[2]int]int{}
+			for _, o := range filter.offsets {
+				hits[[2]int{int((o[0] + 0.5) * float64(cols)), int((o[1] + 0.5) * float64(rows))}]++
+			}
+			for x := 0; x < cols; x++ {
+				for y := 0; y < rows; y++ {
+					if hits[[2]int{x, y}] != 1 {
+						t.Errorf("%d samples: stratum (%d, %d) has %d samples", n, x, y, hits[[2]int{x, y}])
+					}
+				}
+			}
+		}
+	})
+}
+
+func TestThinLens(t *testing.T) {
+	c := NewCamera(201, 101, math.Pi/2)
+	c.SetTransform(ViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0)))
+
+	pinhole := c.perspectiveRay(30.5, 70.5, 0.5, 0.5)
+	c.SetAperture(0.3)
+	c.SetFocalDistance(4)
+	// the camera looks along +z from z = -5, so the focal plane is z = -1
+	focus := pinhole.at((-1 - pinhole.origin[Z]) / pinhole.direction[Z])
+
+	t.Run("The centre of the lens gives the pinhole ray", func(t *testing.T) {
+		r := c.perspectiveRay(30.5, 70.5, 0.5, 0.5)
+		if !r.origin.Equals(pinhole.origin) || !r.direction.Equals(pinhole.direction) {
+			t.Errorf("Expected %v, got %v", pinhole, r)
+		}
+	})
+
+	t.Run("Every ray through the lens meets at the focal plane", func(t *testing.T) {
+		for _, lens := range [][2]float64{{0, 0}, {0.9, 0.1}, {0.25, 0.75}, {1, 0.5}} {
+			r := c.perspectiveRay(30.5, 70.5, lens[0], lens[1])
+			tz := (-1 - r.origin[Z]) / r.direction[Z]
+			if p := r.at(tz); !p.Equals(focus) {
+				t.Errorf("Lens point %v: expected the ray to pass through %v, got %v", lens, focus, p)
+			}
+			if r.origin.Sub(pinhole.origin).Magnitude() > 0.3+EPSILON {
+				t.Errorf("Lens point %v: origin %v is outside the aperture", lens, r.origin)
+			}
+		}
+	})
+
+	t.Run("A focal distance of 0 or less is treated as 1", func(t *testing.T) {
+		c := NewCamera(11, 11, math.Pi/2)
+		for _, d := range []float64{0, -3} {
+			c.SetFocalDistance(d)
+			if c.focalDistance != 1 {
+				t.Errorf("SetFocalDistance(%v): expected a focal distance of 1, got %v", d, c.focalDistance)
+			}
+		}
+	})
+
+	t.Run("Concentric mapping keeps points inside the unit disk", func(t *testing.T) {
+		for u := 0.0; u <= 1; u += 0.125 {
+			for v := 0.0; v <= 1; v += 0.125 {
+				x, y := concentricDisk(u, v)
+				if x*x+y*y > 1+EPSILON {
+					t.Errorf("(%v, %v) mapped to (%v, %v), outside the unit disk", u, v, x, y)
+				}
+			}
+		}
+	})
+}
+
+func TestRayForPixel(t *testing.T) {
+	tests := []struct {
+		name              string
+		transform         func() Matrix
+		px, py            float64
+		origin, direction Vec4
+	}{
+		{"Constructing a ray through the center of the canvas", IdentityMatrix,
+			100, 50, Point4(0, 0, 0), Vector4(0, 0, -1)},
+		{"Constructing a ray through a corner of the canvas", IdentityMatrix,
+			0, 0, Point4(0, 0, 0), Vector4(0.66519, 0.33259, -0.66851)},
+		{"Constructing a ray when the camera is transformed", func() Matrix {
+			rotation, _ := RotationYMatrix(math.Pi / 4)
+			translation, _ := TranslationMatrix(0, -2, 5)
+			m, _ := rotation.MultiplyMatrices(translation)
+			return m
+		}, 100, 50, Point4(0, 2, -5), Vector4(math.Sqrt2/2, 0, -math.Sqrt2/2)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCamera(201, 101, math.Pi/2)
+			c.SetTransform(tt.transform())
+			r, ok := c.rayForPixel(tt.px, tt.py)
+			if !ok {
+				t.Fatalf("Expected a ray through pixel (%v, %v)", tt.px, tt.py)
+			}
+			if !r.origin.Equals(tt.origin) || !r.direction.Equals(tt.direction) {
+				t.Errorf("Expected origin %v and direction %v, got %v and %v", tt.origin, tt.direction, r.origin, r.direction)
+			}
+		})
+	}
+}
+
+func TestProjections(t *testing.T) {
+	t.Run("An orthographic camera fires parallel rays across its view width", func(t *testing.T) {
+		c := NewCamera(201, 101, math.Pi/2)
+		c.SetProjection(OrthographicProjection)
+		c.SetViewWidth(4)
+
+		centre, _ := c.rayForPixel(100, 50)
+		corner, _ := c.rayForPixel(0, 0)
+		if !centre.origin.Equals(Point4(0, 0, 0)) || !centre.direction.Equals(Vector4(0, 0, -1)) {
+			t.Errorf("Expected the centre ray to start at the origin facing -z, got %v", centre)
+		}
+		if !corner.origin.Equals(Point4(1.99005, 0.99502, 0)) || !corner.direction.Equals(Vector4(0, 0, -1)) {
+			t.Errorf("Expected the corner ray to start at (1.99005, 0.99502, 0) facing -z, got %v", corner)
+		}
+	})
+
+	t.Run("An orthographic camera honours the view transform", func(t *testing.T) {
+		c := NewCamera(201, 101, math.Pi/2)
+		c.SetProjection(OrthographicProjection)
+		c.SetTransform(ViewTransform(NewPoint(1, 2, -5), NewPoint(1, 2, 0), NewVector(0, 1, 0)))
+
+		r, _ := c.rayForPixel(100, 50)
+		if !r.origin.Equals(Point4(1, 2, -5)) || !r.direction.Equals(Vector4(0, 0, 1)) {
+			t.Errorf("Expected a ray from (1, 2, -5) facing +z, got %v", r)
+		}
+	})
+
+	t.Run("A fisheye ray's angle grows with its distance from the centre", func(t *testing.T) {
+		c := NewCamera(101, 101, math.Pi)
+		c.SetProjection(FisheyeProjection)
+
+		centre, _ := c.rayThrough(50.5, 50.5, 0.5, 0.5)
+		if !centre.direction.Equals(Vector4(0, 0, -1)) {
+			t.Errorf("Expected the centre ray to face -z, got %v", centre.direction)
+		}
+		edge, ok := c.rayThrough(101, 50.5, 0.5, 0.5)
+		if !ok || !edge.direction.Equals(Vector4(-1, 0, 0)) {
+			t.Errorf("Expected the ray at the edge of a 180 degree fisheye to face -x, got %v", edge.direction)
+		}
+		halfway, _ := c.rayThrough(50.5, 25.25, 0.5, 0.5)
+		if angle := math.Acos(-halfway.direction[Z]); !equalsWithMargin(angle, math.Pi/4) {
+			t.Errorf("Expected a ray halfway to the edge to be 45 degrees off axis, got %v", angle)
+		}
+	})
+
+	t.Run("There is no fisheye ray outside the image circle", func(t *testing.T) {
+		c := NewCamera(101, 101, math.Pi)
+		c.SetProjection(FisheyeProjection)
+		if _, ok := c.rayForPixel(0, 0); ok {
+			t.Errorf("Expected no ray through the corner of a fisheye image")
+		}
+	})
+
+	t.Run("An equirectangular camera sees in every direction", func(t *testing.T) {
+		c := NewCamera(200, 100, math.Pi/2)
+		c.SetProjection(EquirectangularProjection)
+
+		tests := []struct {
+			x, y      float64
+			direction Vec4
+		}{
+			{100, 50, Vector4(0, 0, -1)},
+			{150, 50, Vector4(-1, 0, 0)},
+			{50, 50, Vector4(1, 0, 0)},
+			{0, 50, Vector4(0, 0, 1)},
+			{100, 0, Vector4(0, 1, 0)},
+			{100, 100, Vector4(0, -1, 0)},
+		}
+		for _, tt := range tests {
+			r, _ := c.rayThrough(tt.x, tt.y, 0.5, 0.5)
+			if !r.direction.Equals(tt.direction) {
+				t.Errorf("(%v, %v): expected direction %v, got %v", tt.x, tt.y, tt.direction, r.direction)
+			}
+		}
+	})
+}
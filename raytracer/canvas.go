@@ -2,7 +2,8 @@ package raytracer
 
 import (
 	"fmt"
-	"os"
+	"path/filepath"
+	"strings"
 )
 
 type Canvas struct {
@@ -27,44 +28,36 @@ func (c *Canvas) WritePixel(x, y int, color Color) {
 	}
 }
 
-func (c *Canvas) CanvasToPPM(filename string) error {
-	// Open the file for writing
-	file, err := os.Create(filename)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
+func (c *Canvas) GetWidth() int {
+	return c.width
+}
 
-	// Write the PPM header
-	_, err = fmt.Fprintf(file, "P3\n%d %d\n255\n", c.width, c.Height)
-	if err != nil {
-		return err
+// PixelAt returns the color at (x, y), or black when it is off the canvas.
+func (c *Canvas) PixelAt(x, y int) Color {
+	if x >= 0 && x < c.width && y >= 0 && y < c.Height {
+		return c.pixels[y][x]
 	}
-	for i := 0; i < c.Height; i++ {
-		lineLength := 0
-		for j := 0; j < c.width; j++ {
-			color := c.pixels[i][j]
-			pixelStr := fmt.Sprintf("%d %d %d",
-				int(clamp(color.Tuple[R]*255, 0, 255)),
-				int(clamp(color.Tuple[G]*255, 0, 255)),
-				int(clamp(color.Tuple[B]*255, 0, 255)))
-
-			if lineLength+len(pixelStr)+1 > 70 {
-				fmt.Fprintln(file)
-				lineLength = 0
-			}
+	return NewColor(0, 0, 0)
+}
 
-			if lineLength > 0 {
-				fmt.Fprint(file, " ")
-				lineLength++
-			}
-			fmt.Fprint(file, pixelStr)
-			lineLength += len(pixelStr)
-		}
-		fmt.Fprintln(file)
+// Save writes the canvas to filename in the format named by its extension.
+// ".png" is written as an 8-bit sRGB PNG and ".ppm" as a binary (P6) PPM,
+// also sRGB encoded so that both look the same in a viewer (CanvasToPPM and
+// CanvasToPPMBinary write the values as they are); ".hdr" and ".pfm" keep
+// the unclamped linear colours.
+func (c *Canvas) Save(filename string) error {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".png":
+		return c.CanvasToPNG(filename)
+	case ".ppm":
+		encoded := c.EncodeSRGB()
+		return encoded.CanvasToPPMBinary(filename)
+	case ".hdr":
+		return c.CanvasToHDR(filename)
+	case ".pfm":
+		return c.CanvasToPFM(filename)
 	}
-	fmt.Fprintln(file)
-	return nil
+	return fmt.Errorf("unsupported image format %q", filepath.Ext(filename))
 }
 
 // Helper function to clamp color values between min and max
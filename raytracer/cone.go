@@ -0,0 +1,150 @@
+package raytracer
+
+import "math"
+
+// Cone is a double-napped cone centered on the y axis in object space, whose
+// radius at any y is |y|. Like Cylinder, it can be truncated with Minimum and
+// Maximum and capped with Closed.
+type Cone struct {
+	transform        Matrix
+	inverse          Mat4
+	inverseTranspose Mat4
+	material         *Material
+	Minimum          float64
+	Maximum          float64
+	Closed           bool
+	parent           Shape
+}
+
+func NewCone() *Cone {
+	return &Cone{
+		transform:        IdentityMatrix(),
+		inverse:          Identity4(),
+		inverseTranspose: Identity4(),
+		material:         DefaultMaterial(),
+		Minimum:          math.Inf(-1),
+		Maximum:          math.Inf(1),
+		Closed:           false,
+	}
+}
+
+func (cn *Cone) SetTransform(m Matrix) error {
+	inv, invT, err := invertTransform(m)
+	if err != nil {
+		return err
+	}
+	cn.transform, cn.inverse, cn.inverseTranspose = m, inv, invT
+	return nil
+}
+
+func (cn *Cone) GetTransformMatrix() Matrix {
+	return cn.transform
+}
+
+func (cn *Cone) GetInverseMatrix() Mat4 {
+	return cn.inverse
+}
+
+func (cn *Cone) GetInverseTransposeMatrix() Mat4 {
+	return cn.inverseTranspose
+}
+
+func (cn *Cone) GetMaterial() *Material {
+	return cn.material
+}
+
+func (cn *Cone) SetMaterial(material *Material) {
+	cn.material = material
+}
+
+func (cn *Cone) GetParent() Shape {
+	return cn.parent
+}
+
+func (cn *Cone) setParent(parent Shape) {
+	cn.parent = parent
+}
+
+func (cn *Cone) NormalAt(worldPoint Tuple) Tuple {
+	return normalAt(cn, worldPoint.Vec4(), Intersection{}).Tuple()
+}
+
+func (cn *Cone) localNormalAt(objectPoint Vec4, hit Intersection) Vec4 {
+	dist := objectPoint[X]*objectPoint[X] + objectPoint[Z]*objectPoint[Z]
+	if dist < cn.Maximum*cn.Maximum && objectPoint[Y] >= cn.Maximum-EPSILON {
+		return Vector4(0, 1, 0)
+	} else if dist < cn.Minimum*cn.Minimum && objectPoint[Y] <= cn.Minimum+EPSILON {
+		return Vector4(0, -1, 0)
+	}
+
+	y := math.Sqrt(dist)
+	if objectPoint[Y] > 0 {
+		y = -y
+	}
+	return Vector4(objectPoint[X], y, objectPoint[Z])
+}
+
+func (cn *Cone) Bounds() BoundingBox {
+	limit := math.Max(math.Abs(cn.Minimum), math.Abs(cn.Maximum))
+	return NewBoundingBox(NewPoint(-limit, cn.Minimum, -limit), NewPoint(limit, cn.Maximum, limit))
+}
+
+func (cn *Cone) Intersect(r Ray) []Intersection {
+	//Convert ray into object space
+	localRay := r.transform(cn.inverse)
+	o, d := localRay.origin, localRay.direction
+
+	var xs []Intersection
+
+	a := d[X]*d[X] - d[Y]*d[Y] + d[Z]*d[Z]
+	b := 2*o[X]*d[X] - 2*o[Y]*d[Y] + 2*o[Z]*d[Z]
+	c := o[X]*o[X] - o[Y]*o[Y] + o[Z]*o[Z]
+
+	if math.Abs(a) < EPSILON {
+		// the ray is parallel to one of the cone's halves, so it can cross
+		// the other half at most once
+		if math.Abs(b) >= EPSILON {
+			t := -c / (2 * b)
+			y := o[Y] + t*d[Y]
+			if cn.Minimum < y && y < cn.Maximum {
+				xs = append(xs, Intersection{t: t, o: cn})
+			}
+		}
+	} else {
+		// unlike a cylinder, a ray that misses the sides can still hit a cap
+		disc := b*b - 4*a*c
+		if disc >= 0 {
+			t0 := (-b - math.Sqrt(disc)) / (2 * a)
+			t1 := (-b + math.Sqrt(disc)) / (2 * a)
+			if t0 > t1 {
+				t0, t1 = t1, t0
+			}
+
+			for _, t := range []float64{t0, t1} {
+				y := o[Y] + t*d[Y]
+				if cn.Minimum < y && y < cn.Maximum {
+					xs = append(xs, Intersection{t: t, o: cn})
+				}
+			}
+		}
+	}
+
+	return cn.intersectCaps(localRay, xs)
+}
+
+func (cn *Cone) intersectCaps(r Ray, xs []Intersection) []Intersection {
+	if !cn.Closed || math.Abs(r.direction[Y]) < EPSILON {
+		return xs
+	}
+
+	t := (cn.Minimum - r.origin[Y]) / r.direction[Y]
+	if checkCap(r, t, math.Abs(cn.Minimum)) {
+		xs = append(xs, Intersection{t: t, o: cn})
+	}
+
+	t = (cn.Maximum - r.origin[Y]) / r.direction[Y]
+	if checkCap(r, t, math.Abs(cn.Maximum)) {
+		xs = append(xs, Intersection{t: t, o: cn})
+	}
+	return xs
+}
@@ -0,0 +1,163 @@
+package raytracer
+
+type CSGOperation int
+
+const (
+	CSGUnion CSGOperation = iota
+	CSGIntersection
+	CSGDifference
+)
+
+// CSG combines two shapes with a set operation. Like Group, its transform
+// applies to both operands.
+type CSG struct {
+	transform        Matrix
+	inverse          Mat4
+	inverseTranspose Mat4
+	material         *Material
+	parent           Shape
+	operation        CSGOperation
+	left             Shape
+	right            Shape
+}
+
+func NewCSG(operation CSGOperation, left, right Shape) *CSG {
+	c := &CSG{
+		transform:        IdentityMatrix(),
+		inverse:          Identity4(),
+		inverseTranspose: Identity4(),
+		material:         DefaultMaterial(),
+		operation:        operation,
+		left:             left,
+		right:            right,
+	}
+	left.setParent(c)
+	right.setParent(c)
+	return c
+}
+
+func (c *CSG) SetTransform(m Matrix) error {
+	inv, invT, err := invertTransform(m)
+	if err != nil {
+		return err
+	}
+	c.transform, c.inverse, c.inverseTranspose = m, inv, invT
+	return nil
+}
+
+func (c *CSG) GetTransformMatrix() Matrix {
+	return c.transform
+}
+
+func (c *CSG) GetInverseMatrix() Mat4 {
+	return c.inverse
+}
+
+func (c *CSG) GetInverseTransposeMatrix() Mat4 {
+	return c.inverseTranspose
+}
+
+func (c *CSG) GetMaterial() *Material {
+	return c.material
+}
+
+func (c *CSG) SetMaterial(material *Material) {
+	c.material = material
+}
+
+func (c *CSG) GetParent() Shape {
+	return c.parent
+}
+
+func (c *CSG) setParent(parent Shape) {
+	c.parent = parent
+}
+
+func (c *CSG) GetOperation() CSGOperation {
+	return c.operation
+}
+
+func (c *CSG) GetLeft() Shape {
+	return c.left
+}
+
+func (c *CSG) GetRight() Shape {
+	return c.right
+}
+
+// NormalAt is never called on a CSG: intersections always refer to one of
+// the concrete shapes inside it.
+func (c *CSG) NormalAt(worldPoint Tuple) Tuple {
+	panic("raytracer: NormalAt called on a CSG")
+}
+
+func (c *CSG) localNormalAt(objectPoint Vec4, hit Intersection) Vec4 {
+	panic("raytracer: localNormalAt called on a CSG")
+}
+
+func (c *CSG) Bounds() BoundingBox {
+	return ParentSpaceBounds(c.left).Merge(ParentSpaceBounds(c.right))
+}
+
+func (c *CSG) Intersect(r Ray) []Intersection {
+	localRay := r.transform(c.inverse)
+
+	xs := append(c.left.Intersect(localRay), c.right.Intersect(localRay)...)
+	sortIntersections(xs)
+	return c.FilterIntersections(xs)
+}
+
+// FilterIntersections keeps only the intersections that lie on the surface
+// of the combined shape. xs must be sorted by t.
+func (c *CSG) FilterIntersections(xs []Intersection) []Intersection {
+	// the ray starts outside both operands
+	inLeft, inRight := false, false
+
+	var result []Intersection
+	for _, i := range xs {
+		leftHit := Includes(c.left, i.o)
+		if IntersectionAllowed(c.operation, leftHit, inLeft, inRight) {
+			result = append(result, i)
+		}
+
+		// crossing a surface toggles whether we are inside that operand
+		if leftHit {
+			inLeft = !inLeft
+		} else {
+			inRight = !inRight
+		}
+	}
+	return result
+}
+
+// IntersectionAllowed decides whether a hit on one operand is part of the
+// combined surface. leftHit says which operand was hit; inLeft and inRight
+// say whether the ray is currently inside each operand.
+func IntersectionAllowed(op CSGOperation, leftHit, inLeft, inRight bool) bool {
+	switch op {
+	case CSGUnion:
+		return (leftHit && !inRight) || (!leftHit && !inLeft)
+	case CSGIntersection:
+		return (leftHit && inRight) || (!leftHit && inLeft)
+	case CSGDifference:
+		return (leftHit && !inRight) || (!leftHit && inLeft)
+	}
+	return false
+}
+
+// Includes reports whether b is a, or is contained anywhere inside a when a
+// is a Group or CSG.
+func Includes(a, b Shape) bool {
+	switch s := a.(type) {
+	case *Group:
+		for _, child := range s.children {
+			if Includes(child, b) {
+				return true
+			}
+		}
+		return false
+	case *CSG:
+		return Includes(s.left, b) || Includes(s.right, b)
+	}
+	return a == b
+}
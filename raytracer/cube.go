@@ -0,0 +1,121 @@
+package raytracer
+
+import "math"
+
+// Cube is an axis-aligned box spanning -1 to 1 on every axis in object space.
+type Cube struct {
+	transform        Matrix
+	inverse          Mat4
+	inverseTranspose Mat4
+	material         *Material
+	parent           Shape
+}
+
+func NewCube() *Cube {
+	return &Cube{
+		transform:        IdentityMatrix(),
+		inverse:          Identity4(),
+		inverseTranspose: Identity4(),
+		material:         DefaultMaterial(),
+	}
+}
+
+func (c *Cube) SetTransform(m Matrix) error {
+	inv, invT, err := invertTransform(m)
+	if err != nil {
+		return err
+	}
+	c.transform, c.inverse, c.inverseTranspose = m, inv, invT
+	return nil
+}
+
+func (c *Cube) GetTransformMatrix() Matrix {
+	return c.transform
+}
+
+func (c *Cube) GetInverseMatrix() Mat4 {
+	return c.inverse
+}
+
+func (c *Cube) GetInverseTransposeMatrix() Mat4 {
+	return c.inverseTranspose
+}
+
+func (c *Cube) GetMaterial() *Material {
+	return c.material
+}
+
+func (c *Cube) SetMaterial(material *Material) {
+	c.material = material
+}
+
+func (c *Cube) GetParent() Shape {
+	return c.parent
+}
+
+func (c *Cube) setParent(parent Shape) {
+	c.parent = parent
+}
+
+func (c *Cube) NormalAt(worldPoint Tuple) Tuple {
+	return normalAt(c, worldPoint.Vec4(), Intersection{}).Tuple()
+}
+
+// localNormalAt picks the face the point lies on from whichever component has
+// the largest absolute value.
+func (c *Cube) localNormalAt(objectPoint Vec4, hit Intersection) Vec4 {
+	absX, absY, absZ := math.Abs(objectPoint[X]), math.Abs(objectPoint[Y]), math.Abs(objectPoint[Z])
+	maxc := math.Max(absX, math.Max(absY, absZ))
+
+	if maxc == absX {
+		return Vector4(objectPoint[X], 0, 0)
+	} else if maxc == absY {
+		return Vector4(0, objectPoint[Y], 0)
+	}
+	return Vector4(0, 0, objectPoint[Z])
+}
+
+func (c *Cube) Bounds() BoundingBox {
+	return NewBoundingBox(NewPoint(-1, -1, -1), NewPoint(1, 1, 1))
+}
+
+// Intersect treats the cube as three pairs of parallel planes (slabs). The ray
+// is inside the cube between the largest entry time and the smallest exit time.
+func (c *Cube) Intersect(r Ray) []Intersection {
+	//Convert ray into object space
+	localRay := r.transform(c.inverse)
+
+	xtmin, xtmax := checkAxis(localRay.origin[X], localRay.direction[X], -1, 1)
+	ytmin, ytmax := checkAxis(localRay.origin[Y], localRay.direction[Y], -1, 1)
+	ztmin, ztmax := checkAxis(localRay.origin[Z], localRay.direction[Z], -1, 1)
+
+	tmin := math.Max(xtmin, math.Max(ytmin, ztmin))
+	tmax := math.Min(xtmax, math.Min(ytmax, ztmax))
+
+	if tmin > tmax {
+		return nil
+	}
+	return []Intersection{{t: tmin, o: c}, {t: tmax, o: c}}
+}
+
+// checkAxis returns the times at which a ray crosses the two planes min and
+// max along a single axis. A ray parallel to the planes gets infinite times,
+// so the other axes decide the result.
+func checkAxis(origin, direction, min, max float64) (float64, float64) {
+	tminNumerator := min - origin
+	tmaxNumerator := max - origin
+
+	var tmin, tmax float64
+	if math.Abs(direction) >= EPSILON {
+		tmin = tminNumerator / direction
+		tmax = tmaxNumerator / direction
+	} else {
+		tmin = tminNumerator * math.Inf(1)
+		tmax = tmaxNumerator * math.Inf(1)
+	}
+
+	if tmin > tmax {
+		tmin, tmax = tmax, tmin
+	}
+	return tmin, tmax
+}
@@ -0,0 +1,143 @@
+package raytracer
+
+import "math"
+
+// Cylinder is a cylinder of radius 1 centered on the y axis in object space.
+// It extends infinitely unless Minimum and Maximum truncate it; those bounds
+// are exclusive. Closed caps the truncated ends.
+type Cylinder struct {
+	transform        Matrix
+	inverse          Mat4
+	inverseTranspose Mat4
+	material         *Material
+	Minimum          float64
+	Maximum          float64
+	Closed           bool
+	parent           Shape
+}
+
+func NewCylinder() *Cylinder {
+	return &Cylinder{
+		transform:        IdentityMatrix(),
+		inverse:          Identity4(),
+		inverseTranspose: Identity4(),
+		material:         DefaultMaterial(),
+		Minimum:          math.Inf(-1),
+		Maximum:          math.Inf(1),
+		Closed:           false,
+	}
+}
+
+func (cy *Cylinder) SetTransform(m Matrix) error {
+	inv, invT, err := invertTransform(m)
+	if err != nil {
+		return err
+	}
+	cy.transform, cy.inverse, cy.inverseTranspose = m, inv, invT
+	return nil
+}
+
+func (cy *Cylinder) GetTransformMatrix() Matrix {
+	return cy.transform
+}
+
+func (cy *Cylinder) GetInverseMatrix() Mat4 {
+	return cy.inverse
+}
+
+func (cy *Cylinder) GetInverseTransposeMatrix() Mat4 {
+	return cy.inverseTranspose
+}
+
+func (cy *Cylinder) GetMaterial() *Material {
+	return cy.material
+}
+
+func (cy *Cylinder) SetMaterial(material *Material) {
+	cy.material = material
+}
+
+func (cy *Cylinder) GetParent() Shape {
+	return cy.parent
+}
+
+func (cy *Cylinder) setParent(parent Shape) {
+	cy.parent = parent
+}
+
+func (cy *Cylinder) NormalAt(worldPoint Tuple) Tuple {
+	return normalAt(cy, worldPoint.Vec4(), Intersection{}).Tuple()
+}
+
+func (cy *Cylinder) localNormalAt(objectPoint Vec4, hit Intersection) Vec4 {
+	dist := objectPoint[X]*objectPoint[X] + objectPoint[Z]*objectPoint[Z]
+	if dist < 1 && objectPoint[Y] >= cy.Maximum-EPSILON {
+		return Vector4(0, 1, 0)
+	} else if dist < 1 && objectPoint[Y] <= cy.Minimum+EPSILON {
+		return Vector4(0, -1, 0)
+	}
+	return Vector4(objectPoint[X], 0, objectPoint[Z])
+}
+
+func (cy *Cylinder) Bounds() BoundingBox {
+	return NewBoundingBox(NewPoint(-1, cy.Minimum, -1), NewPoint(1, cy.Maximum, 1))
+}
+
+func (cy *Cylinder) Intersect(r Ray) []Intersection {
+	//Convert ray into object space
+	localRay := r.transform(cy.inverse)
+	o, d := localRay.origin, localRay.direction
+
+	var xs []Intersection
+
+	a := d[X]*d[X] + d[Z]*d[Z]
+	// a ray parallel to the y axis can only hit the caps
+	if math.Abs(a) >= EPSILON {
+		b := 2*o[X]*d[X] + 2*o[Z]*d[Z]
+		c := o[X]*o[X] + o[Z]*o[Z] - 1
+		disc := b*b - 4*a*c
+		if disc < 0 {
+			return nil
+		}
+
+		t0 := (-b - math.Sqrt(disc)) / (2 * a)
+		t1 := (-b + math.Sqrt(disc)) / (2 * a)
+		if t0 > t1 {
+			t0, t1 = t1, t0
+		}
+
+		for _, t := range []float64{t0, t1} {
+			y := o[Y] + t*d[Y]
+			if cy.Minimum < y && y < cy.Maximum {
+				xs = append(xs, Intersection{t: t, o: cy})
+			}
+		}
+	}
+
+	return cy.intersectCaps(localRay, xs)
+}
+
+func (cy *Cylinder) intersectCaps(r Ray, xs []Intersection) []Intersection {
+	if !cy.Closed || math.Abs(r.direction[Y]) < EPSILON {
+		return xs
+	}
+
+	t := (cy.Minimum - r.origin[Y]) / r.direction[Y]
+	if checkCap(r, t, 1) {
+		xs = append(xs, Intersection{t: t, o: cy})
+	}
+
+	t = (cy.Maximum - r.origin[Y]) / r.direction[Y]
+	if checkCap(r, t, 1) {
+		xs = append(xs, Intersection{t: t, o: cy})
+	}
+	return xs
+}
+
+// checkCap reports whether the ray at time t is within radius of the y axis,
+// i.e. whether it lands on an end cap of that radius.
+func checkCap(r Ray, t, radius float64) bool {
+	x := r.origin[X] + t*r.direction[X]
+	z := r.origin[Z] + t*r.direction[Z]
+	return x*x+z*z <= radius*radius+EPSILON
+}
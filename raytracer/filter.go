@@ -0,0 +1,84 @@
+package raytracer
+
+import "math"
+
+// Filter reconstructs a pixel from the samples taken around its centre. The
+// camera spreads its samples over a square of side 2 * Radius() centred on
+// the pixel and averages their colours weighted by Weight.
+type Filter interface {
+	// Radius is how far from the pixel centre, in pixels, samples are taken.
+	Radius() float64
+	// Weight is the filter's value at offset (dx, dy) from the pixel centre.
+	Weight(dx, dy float64) float64
+}
+
+// BoxFilter weights every sample inside the pixel equally.
+type BoxFilter struct{}
+
+func NewBoxFilter() BoxFilter {
+	return BoxFilter{}
+}
+
+func (f BoxFilter) Radius() float64 {
+	return 0.5
+}
+
+func (f BoxFilter) Weight(dx, dy float64) float64 {
+	return 1
+}
+
+// TentFilter falls off linearly from the pixel centre to zero at radius, so
+// samples from neighbouring pixels count for less the further away they are.
+type TentFilter struct {
+	radius float64
+}
+
+// NewTentFilter returns a tent filter reaching zero at radius. A radius of 0
+// or less, which would give every sample zero weight, is treated as 1.
+func NewTentFilter(radius float64) TentFilter {
+	if radius <= 0 {
+		radius = 1
+	}
+	return TentFilter{radius: radius}
+}
+
+func (f TentFilter) Radius() float64 {
+	return f.radius
+}
+
+func (f TentFilter) Weight(dx, dy float64) float64 {
+	return math.Max(0, f.radius-math.Abs(dx)) * math.Max(0, f.radius-math.Abs(dy))
+}
+
+// GaussianFilter weights samples with a Gaussian of falloff alpha, shifted
+// down so that it reaches zero at radius rather than being cut off there.
+type GaussianFilter struct {
+	radius float64
+	alpha  float64
+	edge   float64
+}
+
+// NewGaussianFilter returns a Gaussian filter reaching zero at radius. A
+// radius of 0 or less is treated as 1.5 and an alpha of 0 or less as 2, as
+// either would give every sample zero weight.
+func NewGaussianFilter(radius, alpha float64) GaussianFilter {
+	if radius <= 0 {
+		radius = 1.5
+	}
+	if alpha <= 0 {
+		alpha = 2
+	}
+	return GaussianFilter{radius: radius, alpha: alpha, edge: math.Exp(-alpha * radius * radius)}
+}
+
+func (f GaussianFilter) Radius() float64 {
+	return f.radius
+}
+
+func (f GaussianFilter) Weight(dx, dy float64) float64 {
+	return f.gaussian(dx) * f.gaussian(dy)
+}
+
+func (f GaussianFilter) gaussian(d float64) float64 {
+	return math.Max(0, math.Exp(-f.alpha*d*d)-f.edge)
+}
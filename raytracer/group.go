@@ -0,0 +1,109 @@
+package raytracer
+
+// Group is a shape that only holds other shapes. Its transform applies to all
+// of its children, so a composite object can be built once and then moved,
+// scaled or rotated as a unit.
+//
+// The first time a group is intersected it builds a bounding volume hierarchy
+// over its children, so large meshes are not tested triangle by triangle.
+// Adding a child discards it; changing a child's transform afterwards does
+// not, so finish building the group before rendering. Intersecting a group
+// from several goroutines at once is safe.
+type Group struct {
+	transform        Matrix
+	inverse          Mat4
+	inverseTranspose Mat4
+	material         *Material
+	parent           Shape
+	children         []Shape
+	bvh              *lazyBVH
+}
+
+func NewGroup() *Group {
+	return &Group{
+		transform:        IdentityMatrix(),
+		inverse:          Identity4(),
+		inverseTranspose: Identity4(),
+		material:         DefaultMaterial(),
+		children:         []Shape{},
+		bvh:              &lazyBVH{},
+	}
+}
+
+func (g *Group) SetTransform(m Matrix) error {
+	inv, invT, err := invertTransform(m)
+	if err != nil {
+		return err
+	}
+	g.transform, g.inverse, g.inverseTranspose = m, inv, invT
+	return nil
+}
+
+func (g *Group) GetTransformMatrix() Matrix {
+	return g.transform
+}
+
+func (g *Group) GetInverseMatrix() Mat4 {
+	return g.inverse
+}
+
+func (g *Group) GetInverseTransposeMatrix() Mat4 {
+	return g.inverseTranspose
+}
+
+func (g *Group) GetMaterial() *Material {
+	return g.material
+}
+
+func (g *Group) SetMaterial(material *Material) {
+	g.material = material
+}
+
+func (g *Group) GetParent() Shape {
+	return g.parent
+}
+
+func (g *Group) setParent(parent Shape) {
+	g.parent = parent
+}
+
+func (g *Group) GetChildren() []Shape {
+	return g.children
+}
+
+// AddChild adds s to the group and makes the group its parent.
+func (g *Group) AddChild(s Shape) {
+	s.setParent(g)
+	g.children = append(g.children, s)
+	g.bvh = &lazyBVH{}
+}
+
+func (g *Group) accelerator() *bvh {
+	return g.bvh.get(g.children)
+}
+
+// Bounds returns the box around all of the group's children.
+func (g *Group) Bounds() BoundingBox {
+	return g.accelerator().bounds()
+}
+
+// NormalAt is never called on a group: intersections always refer to the
+// concrete child that was hit, never to the group itself.
+func (g *Group) NormalAt(worldPoint Tuple) Tuple {
+	panic("raytracer: NormalAt called on a Group")
+}
+
+func (g *Group) localNormalAt(objectPoint Vec4, hit Intersection) Vec4 {
+	panic("raytracer: localNormalAt called on a Group")
+}
+
+// Intersect moves the ray into group space and intersects it with the
+// children whose bounds it passes through. Each child then applies its own
+// transform on top.
+func (g *Group) Intersect(r Ray) []Intersection {
+	localRay := r.transform(g.inverse)
+
+	xs := g.accelerator().intersect(localRay, nil)
+	sortIntersections(xs)
+	return xs
+}
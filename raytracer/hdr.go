@@ -0,0 +1,71 @@
+package raytracer
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// CanvasToHDR writes the canvas to filename as a Radiance RGBE (.hdr) image.
+func (c *Canvas) CanvasToHDR(filename string) error {
+	return writeFile(filename, c.WriteHDR)
+}
+
+// CanvasToPFM writes the canvas to filename as a colour Portable Float Map.
+func (c *Canvas) CanvasToPFM(filename string) error {
+	return writeFile(filename, c.WritePFM)
+}
+
+// WriteHDR writes the canvas to w as an uncompressed Radiance RGBE image.
+// Channels are not clamped above 1, so over-bright highlights survive;
+// negative values are written as 0, which RGBE cannot represent.
+func (c *Canvas) WriteHDR(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "#?RADIANCE\nFORMAT=32-bit_rle_rgbe\n\n-Y %d +X %d\n", c.Height, c.width)
+
+	for i := 0; i < c.Height; i++ {
+		for j := 0; j < c.width; j++ {
+			rgbe := colorToRGBE(c.pixels[i][j])
+			out.Write(rgbe[:])
+		}
+	}
+	return out.Flush()
+}
+
+// colorToRGBE packs color into three 8-bit mantissas that share the exponent
+// of its brightest channel.
+func colorToRGBE(color Color) [4]byte {
+	r := math.Max(color[R], 0)
+	g := math.Max(color[G], 0)
+	b := math.Max(color[B], 0)
+
+	brightest := math.Max(r, math.Max(g, b))
+	if brightest < 1e-32 {
+		return [4]byte{}
+	}
+	mantissa, exponent := math.Frexp(brightest)
+	scale := mantissa * 256 / brightest
+	return [4]byte{byte(r * scale), byte(g * scale), byte(b * scale), byte(exponent + 128)}
+}
+
+// WritePFM writes the canvas to w as a colour Portable Float Map: one
+// little-endian float32 per channel, unclamped, with the bottom row first as
+// the format requires.
+func (c *Canvas) WritePFM(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "PF\n%d %d\n-1.0\n", c.width, c.Height)
+
+	var buf [12]byte
+	for i := c.Height - 1; i >= 0; i-- {
+		for j := 0; j < c.width; j++ {
+			p := c.pixels[i][j]
+			binary.LittleEndian.PutUint32(buf[0:], math.Float32bits(float32(p[R])))
+			binary.LittleEndian.PutUint32(buf[4:], math.Float32bits(float32(p[G])))
+			binary.LittleEndian.PutUint32(buf[8:], math.Float32bits(float32(p[B])))
+			out.Write(buf[:])
+		}
+	}
+	return out.Flush()
+}
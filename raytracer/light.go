@@ -0,0 +1,228 @@
+package raytracer
+
+import (
+	"math"
+	"math/rand/v2"
+)
+
+// LightSource is a light that a World can be lit by. A light is made up of
+// one or more samples; shading averages over them and shadow tests count how
+// many of them are visible from a point. Lights that place their samples at
+// random draw from rng, so that renders stay reproducible.
+type LightSource interface {
+	GetIntensity() Color
+
+	sampleCount() int
+	sampleFrom(i int, p Vec4, rng *rand.Rand) lightSample
+}
+
+// fixedRNG returns a random source with a fixed seed for the exported
+// shading functions, which are not handed one by a Camera. It keeps their
+// jittered area lights repeatable.
+func fixedRNG() *rand.Rand {
+	return rand.New(rand.NewPCG(0, 0))
+}
+
+// lightSample is one sample of a light as seen from a point.
+type lightSample struct {
+	// direction is the unit vector from the point towards the light.
+	direction Vec4
+	// distance is how far a shadow ray has to be clear for the point to be
+	// lit. It is infinite for a directional light.
+	distance float64
+	// attenuation scales the light's intensity for falloff with distance or
+	// away from the axis of a spot light.
+	attenuation float64
+}
+
+// sampleTowards builds the sample for a light at lightPosition seen from p.
+func sampleTowards(lightPosition, p Vec4, inverseSquare bool) lightSample {
+	v := lightPosition.Sub(p)
+	distance := v.Magnitude()
+	attenuation := 1.0
+	if inverseSquare {
+		attenuation = 1 / (distance * distance)
+	}
+	return lightSample{direction: v.Scale(1 / distance), distance: distance, attenuation: attenuation}
+}
+
+// Light is a point light: every ray towards it converges on Position, so
+// its shadows have hard edges. With InverseSquare set, its intensity falls
+// off with the square of the distance, so Intensity is the intensity one
+// unit away from the light.
+type Light struct {
+	Position      Tuple
+	Intensity     Color
+	InverseSquare bool
+}
+
+func (l Light) GetIntensity() Color {
+	return l.Intensity
+}
+
+func (l Light) sampleCount() int {
+	return 1
+}
+
+func (l Light) sampleFrom(i int, p Vec4, rng *rand.Rand) lightSample {
+	return sampleTowards(l.Position.Vec4(), p, l.InverseSquare)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// DirectionalLight is a light infinitely far away, such as the sun. Its rays
+// all travel parallel to Direction and it is never attenuated.
+type DirectionalLight struct {
+	Direction Tuple
+	Intensity Color
+}
+
+func (d DirectionalLight) GetIntensity() Color {
+	return d.Intensity
+}
+
+func (d DirectionalLight) sampleCount() int {
+	return 1
+}
+
+func (d DirectionalLight) sampleFrom(i int, p Vec4, rng *rand.Rand) lightSample {
+	return lightSample{
+		direction:   d.Direction.Vec4().Normalize().Negate(),
+		distance:    math.Inf(1),
+		attenuation: 1,
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// SpotLight is a point light that only shines in a cone around direction.
+// Inside innerAngle it is at full intensity, outside outerAngle it gives no
+// light, and in between it fades out smoothly. Both angles are measured in
+// radians from the axis of the cone.
+type SpotLight struct {
+	position      Vec4
+	direction     Vec4
+	cosInner      float64
+	cosOuter      float64
+	intensity     Color
+	inverseSquare bool
+}
+
+func NewSpotLight(position, direction Tuple, innerAngle, outerAngle float64, intensity Color) *SpotLight {
+	return &SpotLight{
+		position:  position.Vec4(),
+		direction: direction.Vec4().Normalize(),
+		cosInner:  math.Cos(innerAngle),
+		cosOuter:  math.Cos(outerAngle),
+		intensity: intensity,
+	}
+}
+
+func (s *SpotLight) GetIntensity() Color {
+	return s.intensity
+}
+
+func (s *SpotLight) GetPosition() Tuple {
+	return s.position.Tuple()
+}
+
+// SetInverseSquare makes the light fall off with the square of the distance,
+// as a Light with InverseSquare does.
+func (s *SpotLight) SetInverseSquare(inverseSquare bool) {
+	s.inverseSquare = inverseSquare
+}
+
+func (s *SpotLight) sampleCount() int {
+	return 1
+}
+
+func (s *SpotLight) sampleFrom(i int, p Vec4, rng *rand.Rand) lightSample {
+	sample := sampleTowards(s.position, p, s.inverseSquare)
+	sample.attenuation *= s.coneFalloff(sample.direction.Negate().Dot(s.direction))
+	return sample
+}
+
+// coneFalloff returns how much of the light reaches a point whose direction
+// from the light makes an angle with cosine cos with the axis of the cone.
+func (s *SpotLight) coneFalloff(cos float64) float64 {
+	if cos >= s.cosInner {
+		return 1
+	}
+	if cos <= s.cosOuter {
+		return 0
+	}
+	t := (cos - s.cosOuter) / (s.cosInner - s.cosOuter)
+	return t * t * (3 - 2*t)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// AreaLight is a rectangular light spanning corner to corner+uvec+vvec,
+// divided into a usteps by vsteps grid of cells with one sample per cell.
+// Points that can only see some of the cells are in soft, partial shadow.
+type AreaLight struct {
+	corner    Vec4
+	uvec      Vec4
+	vvec      Vec4
+	usteps    int
+	vsteps    int
+	position  Tuple
+	intensity Color
+	jitter    bool
+}
+
+// NewAreaLight creates an area light with edges fullUVec and fullVVec. Each
+// cell is sampled at its centre until jitter is turned on with SetJitter.
+// Step counts below 1 are treated as 1.
+func NewAreaLight(corner, fullUVec Tuple, usteps int, fullVVec Tuple, vsteps int, intensity Color) *AreaLight {
+	usteps, vsteps = max(usteps, 1), max(vsteps, 1)
+	c, u, v := corner.Vec4(), fullUVec.Vec4(), fullVVec.Vec4()
+	return &AreaLight{
+		corner:    c,
+		uvec:      u.Scale(1 / float64(usteps)),
+		vvec:      v.Scale(1 / float64(vsteps)),
+		usteps:    usteps,
+		vsteps:    vsteps,
+		position:  c.Add(u.Scale(0.5)).Add(v.Scale(0.5)).Tuple(),
+		intensity: intensity,
+	}
+}
+
+func (a *AreaLight) GetIntensity() Color {
+	return a.intensity
+}
+
+func (a *AreaLight) GetPosition() Tuple {
+	return a.position
+}
+
+// SetJitter makes each sample land at a random point inside its cell rather
+// than at its centre, which trades the banding of a regular grid for noise.
+func (a *AreaLight) SetJitter(jitter bool) {
+	a.jitter = jitter
+}
+
+func (a *AreaLight) sampleCount() int {
+	return a.usteps * a.vsteps
+}
+
+func (a *AreaLight) sampleFrom(i int, p Vec4, rng *rand.Rand) lightSample {
+	return sampleTowards(a.pointOnLight(i%a.usteps, i/a.usteps, rng), p, false)
+}
+
+// PointOnLight returns the sample point in cell (u, v) of the light. A
+// jittered light takes its offset within the cell from rng, which may only be
+// nil when jitter is off.
+func (a *AreaLight) PointOnLight(u, v int, rng *rand.Rand) Tuple {
+	return a.pointOnLight(u, v, rng).Tuple()
+}
+
+func (a *AreaLight) pointOnLight(u, v int, rng *rand.Rand) Vec4 {
+	du, dv := 0.5, 0.5
+	if a.jitter {
+		du, dv = rng.Float64(), rng.Float64()
+	}
+	return a.corner.
+		Add(a.uvec.Scale(float64(u) + du)).
+		Add(a.vvec.Scale(float64(v) + dv))
+}
@@ -0,0 +1,142 @@
+package raytracer
+
+import (
+	"fmt"
+	"math"
+)
+
+// Mat4 is a fixed-size, value-type 4x4 matrix. Matrix remains the general,
+// error-checked type for building transforms; shapes and the camera convert
+// their transforms to Mat4 once so rendering never allocates for them.
+type Mat4 [4][4]float64
+
+func Identity4() Mat4 {
+	return Mat4{
+		{1, 0, 0, 0},
+		{0, 1, 0, 0},
+		{0, 0, 1, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+// Mat4 converts m to a Mat4. It returns an error unless m is 4x4.
+func (m Matrix) Mat4() (Mat4, error) {
+	if m.width != 4 || m.height != 4 {
+		return Mat4{}, fmt.Errorf("matrix must be 4x4 to convert to Mat4, got %dx%d", m.height, m.width)
+	}
+	var n Mat4
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 4; col++ {
+			n[row][col] = m.data[row*4+col]
+		}
+	}
+	return n, nil
+}
+
+func (m Mat4) Matrix() Matrix {
+	n := NewMatrix(4, 4)
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 4; col++ {
+			n.data[row*4+col] = m[row][col]
+		}
+	}
+	return n
+}
+
+func (m Mat4) Equals(other Mat4) bool {
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 4; col++ {
+			if !equalsWithMargin(m[row][col], other[row][col]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (m Mat4) MultiplyMatrices(other Mat4) Mat4 {
+	var n Mat4
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 4; col++ {
+			n[row][col] = m[row][0]*other[0][col] +
+				m[row][1]*other[1][col] +
+				m[row][2]*other[2][col] +
+				m[row][3]*other[3][col]
+		}
+	}
+	return n
+}
+
+func (m Mat4) MultiplyWithVec(v Vec4) Vec4 {
+	return Vec4{
+		m[0][0]*v[X] + m[0][1]*v[Y] + m[0][2]*v[Z] + m[0][3]*v[W],
+		m[1][0]*v[X] + m[1][1]*v[Y] + m[1][2]*v[Z] + m[1][3]*v[W],
+		m[2][0]*v[X] + m[2][1]*v[Y] + m[2][2]*v[Z] + m[2][3]*v[W],
+		m[3][0]*v[X] + m[3][1]*v[Y] + m[3][2]*v[Z] + m[3][3]*v[W],
+	}
+}
+
+func (m Mat4) Transpose() Mat4 {
+	var n Mat4
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 4; col++ {
+			n[col][row] = m[row][col]
+		}
+	}
+	return n
+}
+
+func rowNorm(row [4]float64) float64 {
+	return math.Sqrt(row[0]*row[0] + row[1]*row[1] + row[2]*row[2] + row[3]*row[3])
+}
+
+// Inverse inverts m using the 2x2 sub-determinants of its top and bottom
+// halves, which is much cheaper than Matrix's cofactor expansion.
+func (m Mat4) Inverse() (Mat4, error) {
+	s0 := m[0][0]*m[1][1] - m[1][0]*m[0][1]
+	s1 := m[0][0]*m[1][2] - m[1][0]*m[0][2]
+	s2 := m[0][0]*m[1][3] - m[1][0]*m[0][3]
+	s3 := m[0][1]*m[1][2] - m[1][1]*m[0][2]
+	s4 := m[0][1]*m[1][3] - m[1][1]*m[0][3]
+	s5 := m[0][2]*m[1][3] - m[1][2]*m[0][3]
+
+	c5 := m[2][2]*m[3][3] - m[3][2]*m[2][3]
+	c4 := m[2][1]*m[3][3] - m[3][1]*m[2][3]
+	c3 := m[2][1]*m[3][2] - m[3][1]*m[2][2]
+	c2 := m[2][0]*m[3][3] - m[3][0]*m[2][3]
+	c1 := m[2][0]*m[3][2] - m[3][0]*m[2][2]
+	c0 := m[2][0]*m[3][1] - m[3][0]*m[2][1]
+
+	det := s0*c5 - s1*c4 + s2*c3 + s3*c2 - s4*c1 + s5*c0
+	if isSingular(det, rowNorm(m[0]), rowNorm(m[1]), rowNorm(m[2]), rowNorm(m[3])) {
+		return Mat4{}, fmt.Errorf("matrix is not invertible (determinant is zero)")
+	}
+	inv := 1 / det
+
+	return Mat4{
+		{
+			(m[1][1]*c5 - m[1][2]*c4 + m[1][3]*c3) * inv,
+			(-m[0][1]*c5 + m[0][2]*c4 - m[0][3]*c3) * inv,
+			(m[3][1]*s5 - m[3][2]*s4 + m[3][3]*s3) * inv,
+			(-m[2][1]*s5 + m[2][2]*s4 - m[2][3]*s3) * inv,
+		},
+		{
+			(-m[1][0]*c5 + m[1][2]*c2 - m[1][3]*c1) * inv,
+			(m[0][0]*c5 - m[0][2]*c2 + m[0][3]*c1) * inv,
+			(-m[3][0]*s5 + m[3][2]*s2 - m[3][3]*s1) * inv,
+			(m[2][0]*s5 - m[2][2]*s2 + m[2][3]*s1) * inv,
+		},
+		{
+			(m[1][0]*c4 - m[1][1]*c2 + m[1][3]*c0) * inv,
+			(-m[0][0]*c4 + m[0][1]*c2 - m[0][3]*c0) * inv,
+			(m[3][0]*s4 - m[3][1]*s2 + m[3][3]*s0) * inv,
+			(-m[2][0]*s4 + m[2][1]*s2 - m[2][3]*s0) * inv,
+		},
+		{
+			(-m[1][0]*c3 + m[1][1]*c1 - m[1][2]*c0) * inv,
+			(m[0][0]*c3 - m[0][1]*c1 + m[0][2]*c0) * inv,
+			(-m[3][0]*s3 + m[3][1]*s1 - m[3][2]*s0) * inv,
+			(m[2][0]*s3 - m[2][1]*s1 + m[2][2]*s0) * inv,
+		},
+	}, nil
+}
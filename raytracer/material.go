@@ -1,30 +1,32 @@
 package raytracer
 
-import "math"
-
-type Light struct {
-	Position  Tuple
-	Intensity Color
-}
+import (
+	"math"
+	"math/rand/v2"
+)
 
 type Material struct {
-	color      Color
-	ambient    float64
-	diffuse    float64
-	specular   float64
-	shininess  float64
-	Pattern    Pattern
-	reflective float64
+	color           Color
+	ambient         float64
+	diffuse         float64
+	specular        float64
+	shininess       float64
+	Pattern         Pattern
+	reflective      float64
+	transparency    float64
+	refractiveIndex float64
 }
 
 func DefaultMaterial() *Material {
 	return &Material{
-		color:      NewColor(1, 1, 1),
-		ambient:    0.1,
-		diffuse:    0.9,
-		specular:   0.9,
-		shininess:  200.0,
-		reflective: 0.0,
+		color:           NewColor(1, 1, 1),
+		ambient:         0.1,
+		diffuse:         0.9,
+		specular:        0.9,
+		shininess:       200.0,
+		reflective:      0.0,
+		transparency:    0.0,
+		refractiveIndex: 1.0,
 	}
 }
 
@@ -54,8 +56,25 @@ func (m *Material) SetShininess(f float64) {
 	m.shininess = f
 }
 
-func Lighting(material Material, object Shape, light Light, point, eyev, normalv Tuple, inShadow bool) Color {
-	var diffuse, specular, ambient, color Color
+func (m *Material) SetTransparency(f float64) {
+	m.transparency = f
+}
+
+func (m *Material) SetRefractiveIndex(f float64) {
+	m.refractiveIndex = f
+}
+
+// Lighting shades point with the Phong model. intensity is the fraction of
+// the light that reaches the point, as returned by World.IntensityAt: 0 leaves
+// only the ambient term and 1 is fully lit. Every term is averaged over the
+// samples of the light and scaled by their attenuation.
+func Lighting(material Material, object Shape, light LightSource, point, eyev, normalv Tuple, intensity float64) Color {
+	return lighting(material, object, light, point.Vec4(), eyev.Vec4(), normalv.Vec4(), intensity, fixedRNG())
+}
+
+func lighting(material Material, object Shape, light LightSource, point, eyev, normalv Vec4, intensity float64,
+	rng *rand.Rand) Color {
+	var color Color
 
 	if material.Pattern != nil {
 		color = material.Pattern.PatternAtObject(object, point)
@@ -63,33 +82,35 @@ func Lighting(material Material, object Shape, light Light, point, eyev, normalv
 		color = material.color
 	}
 
-	effectiveColor := color.MultiplyOtherColor(light.Intensity)
-
-	lightv, _ := light.Position.Subtract(point)
-	lightv, _ = lightv.Normalize()
-
-	ambient = effectiveColor.MultiplyByScalar(material.ambient)
-	if inShadow {
-		return ambient
-	}
-
-	lightDotNormal, _ := Dot(lightv, normalv)
-	if lightDotNormal < 0 {
-		diffuse = NewColor(0, 0, 0)
-		specular = NewColor(0, 0, 0)
-	} else {
-		diffuse = effectiveColor.MultiplyByScalar(material.diffuse).MultiplyByScalar(lightDotNormal)
+	lightIntensity := light.GetIntensity()
+	effectiveColor := color.MultiplyOtherColor(lightIntensity)
+
+	// ambient light is attenuated like the rest, so an inverse-square light
+	// does not flood the scene with it and a spot light gives none outside
+	// its cone
+	attenuation := 0.0
+	sum := NewColor(0, 0, 0)
+	samples := light.sampleCount()
+	for i := 0; i < samples; i++ {
+		sample := light.sampleFrom(i, point, rng)
+		attenuation += sample.attenuation
+		if intensity == 0 {
+			continue
+		}
+		lightv := sample.direction
 
-		reflectv, _ := Reflect(lightv.Multiply(-1), normalv)
-		reflectDotEye, _ := Dot(reflectv, eyev)
+		lightDotNormal := lightv.Dot(normalv)
+		if lightDotNormal < 0 || sample.attenuation == 0 {
+			continue
+		}
+		sum = sum.AddColor(effectiveColor.MultiplyByScalar(material.diffuse * lightDotNormal * sample.attenuation))
 
-		if reflectDotEye < 0 {
-			specular = NewColor(0, 0, 0)
-		} else {
+		reflectDotEye := lightv.Negate().Reflect(normalv).Dot(eyev)
+		if reflectDotEye > 0 {
 			factor := math.Pow(reflectDotEye, material.shininess)
-			specular = light.Intensity.MultiplyByScalar(material.specular).MultiplyByScalar(factor)
+			sum = sum.AddColor(lightIntensity.MultiplyByScalar(material.specular * factor * sample.attenuation))
 		}
-
 	}
-	return ambient.AddColor(diffuse).AddColor(specular)
+	ambient := effectiveColor.MultiplyByScalar(material.ambient * attenuation / float64(samples))
+	return ambient.AddColor(sum.MultiplyByScalar(intensity / float64(samples)))
 }
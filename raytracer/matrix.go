@@ -127,10 +127,41 @@ func (m Matrix) Transpose() (Matrix, error) {
 	return n, nil
 }
 
+// singularTolerance is how small a determinant may be, relative to the
+// product of its matrix's row norms, before the matrix counts as singular.
+// The product bounds the size of the determinant (Hadamard's inequality), and
+// equals it when the rows are orthogonal, so the ratio measures how nearly
+// the rows depend on each other regardless of the matrix's scale: a uniform
+// scale of 1e-4 inverts, while rows that are dependent up to rounding error
+// do not.
+const singularTolerance = 1e-12
+
+// isSingular reports whether det is too small to invert by, given the norms
+// of the rows of the matrix it belongs to.
+func isSingular(det float64, rowNorms ...float64) bool {
+	bound := 1.0
+	for _, n := range rowNorms {
+		bound *= n
+	}
+	return math.Abs(det) <= singularTolerance*bound
+}
+
+func (m Matrix) rowNorms() []float64 {
+	norms := make([]float64, m.height)
+	for row := range norms {
+		sum := 0.0
+		for _, v := range m.data[row*m.width : (row+1)*m.width] {
+			sum += v * v
+		}
+		norms[row] = math.Sqrt(sum)
+	}
+	return norms
+}
+
 func (m Matrix) Inverse() (Matrix, error) {
 	det, _ := m.determinant()
 
-	if equalsWithMargin(det, 0.0) {
+	if isSingular(det, m.rowNorms()...) {
 		return Matrix{}, fmt.Errorf("matrix is not invertible (determinant is zero)")
 	}
 
@@ -147,6 +178,20 @@ func (m Matrix) Inverse() (Matrix, error) {
 	return newMatrix, nil
 }
 
+// invertTransform returns the inverse and inverse-transpose of m, which is
+// what shapes need to move rays into object space and normals back out.
+func invertTransform(m Matrix) (Mat4, Mat4, error) {
+	m4, err := m.Mat4()
+	if err != nil {
+		return Mat4{}, Mat4{}, err
+	}
+	inv, err := m4.Inverse()
+	if err != nil {
+		return Mat4{}, Mat4{}, err
+	}
+	return inv, inv.Transpose(), nil
+}
+
 func IdentityMatrix() Matrix {
 	n := NewMatrix(4, 4)
 	n, _ = n.Set(0, 0, 1)
@@ -0,0 +1,203 @@
+package raytracer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ObjFile holds the result of parsing a Wavefront OBJ file. Vertex data is
+// stored in file order, so OBJ's 1-based index n refers to element n-1.
+type ObjFile struct {
+	Vertices      []Tuple
+	Normals       []Tuple
+	TextureCoords []Tuple
+
+	// DefaultGroup holds faces that appear before any "g" or "o" statement.
+	DefaultGroup *Group
+	// Groups holds the named groups from "g" and "o" statements.
+	Groups     map[string]*Group
+	groupOrder []string
+
+	// IgnoredLines lists the line numbers of statements the parser does not
+	// understand, such as materials or smoothing groups.
+	IgnoredLines []int
+}
+
+// ParseObjFile opens filename and parses it with ParseObj.
+func ParseObjFile(filename string) (*ObjFile, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ParseObj(file)
+}
+
+// ParseObj reads OBJ data from r. It understands vertices (v), vertex normals
+// (vn), texture coordinates (vt), faces (f) and groups (g, o). Faces with more
+// than three vertices are split into a fan of triangles. When a face has
+// vertex normals it becomes a SmoothTriangle, otherwise a Triangle.
+//
+// Anything else is skipped and its line number recorded in IgnoredLines.
+// Malformed statements and out-of-range indices are returned as errors that
+// name the offending line.
+func ParseObj(r io.Reader) (*ObjFile, error) {
+	obj := &ObjFile{
+		DefaultGroup: NewGroup(),
+		Groups:       map[string]*Group{},
+	}
+	current := obj.DefaultGroup
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		var err error
+		switch fields[0] {
+		case "v":
+			var v []float64
+			if v, err = parseObjFloats(fields[1:], 3, 4); err == nil {
+				obj.Vertices = append(obj.Vertices, NewPoint(v[0], v[1], v[2]))
+			}
+		case "vn":
+			var v []float64
+			if v, err = parseObjFloats(fields[1:], 3, 3); err == nil {
+				obj.Normals = append(obj.Normals, NewVector(v[0], v[1], v[2]))
+			}
+		case "vt":
+			var v []float64
+			if v, err = parseObjFloats(fields[1:], 1, 3); err == nil {
+				for len(v) < 3 {
+					v = append(v, 0)
+				}
+				obj.TextureCoords = append(obj.TextureCoords, NewPoint(v[0], v[1], v[2]))
+			}
+		case "f":
+			err = obj.parseFace(fields[1:], current)
+		case "g", "o":
+			if len(fields) < 2 {
+				err = fmt.Errorf("%s statement needs a name", fields[0])
+				break
+			}
+			name := strings.Join(fields[1:], " ")
+			g, ok := obj.Groups[name]
+			if !ok {
+				g = NewGroup()
+				obj.Groups[name] = g
+				obj.groupOrder = append(obj.groupOrder, name)
+			}
+			current = g
+		default:
+			obj.IgnoredLines = append(obj.IgnoredLines, lineNumber)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("obj line %d: %v", lineNumber, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// ToGroup returns a single group holding the default group (when it has any
+// faces) and every named group in the order they first appear, ready to be
+// passed to World.AddObject.
+func (obj *ObjFile) ToGroup() *Group {
+	g := NewGroup()
+	if len(obj.DefaultGroup.GetChildren()) > 0 {
+		g.AddChild(obj.DefaultGroup)
+	}
+	for _, name := range obj.groupOrder {
+		g.AddChild(obj.Groups[name])
+	}
+	return g
+}
+
+func (obj *ObjFile) parseFace(args []string, g *Group) error {
+	if len(args) < 3 {
+		return fmt.Errorf("face needs at least 3 vertices, got %d", len(args))
+	}
+
+	points := make([]Tuple, len(args))
+	normals := make([]Tuple, len(args))
+	smooth := true
+	for i, arg := range args {
+		// each vertex is v, v/vt, v//vn or v/vt/vn
+		parts := strings.Split(arg, "/")
+		if len(parts) > 3 {
+			return fmt.Errorf("malformed face vertex %q", arg)
+		}
+
+		var err error
+		if points[i], err = objIndex(parts[0], obj.Vertices, "vertex"); err != nil {
+			return err
+		}
+		if len(parts) > 1 && parts[1] != "" {
+			if _, err = objIndex(parts[1], obj.TextureCoords, "texture coordinate"); err != nil {
+				return err
+			}
+		}
+		if len(parts) > 2 && parts[2] != "" {
+			if normals[i], err = objIndex(parts[2], obj.Normals, "normal"); err != nil {
+				return err
+			}
+		} else {
+			smooth = false
+		}
+	}
+
+	// fan triangulation around the first vertex
+	for i := 1; i < len(points)-1; i++ {
+		if smooth {
+			g.AddChild(NewSmoothTriangle(points[0], points[i], points[i+1], normals[0], normals[i], normals[i+1]))
+		} else {
+			g.AddChild(NewTriangle(points[0], points[i], points[i+1]))
+		}
+	}
+	return nil
+}
+
+// objIndex resolves a 1-based OBJ index into list. Negative indices count
+// back from the most recently defined element, as the format allows.
+func objIndex(s string, list []Tuple, kind string) (Tuple, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s index %q", kind, s)
+	}
+	if n < 0 {
+		n = len(list) + n + 1
+	}
+	if n < 1 || n > len(list) {
+		return nil, fmt.Errorf("%s index %s out of range (have %d)", kind, s, len(list))
+	}
+	return list[n-1], nil
+}
+
+func parseObjFloats(args []string, min, max int) ([]float64, error) {
+	if len(args) < min || len(args) > max {
+		if min == max {
+			return nil, fmt.Errorf("expected %d numbers, got %d", min, len(args))
+		}
+		return nil, fmt.Errorf("expected %d to %d numbers, got %d", min, max, len(args))
+	}
+	values := make([]float64, len(args))
+	for i, arg := range args {
+		f, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", arg)
+		}
+		values[i] = f
+	}
+	return values, nil
+}
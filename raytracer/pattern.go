@@ -3,19 +3,19 @@ package raytracer
 import "math"
 
 type Pattern interface {
-	PatternAtObject(obj Shape, point Tuple) Color
-	PatternAt(point Tuple) Color
+	PatternAtObject(obj Shape, point Vec4) Color
+	PatternAt(point Vec4) Color
 	GetTransform() Matrix
-	SetTransform(m Matrix)
+	GetInverse() Mat4
+	// SetTransform sets the pattern's transform and caches its inverse. It
+	// returns an error, leaving the pattern unchanged, when m cannot be
+	// inverted.
+	SetTransform(m Matrix) error
 }
 
 // Helper to handle world → object → pattern space transform
-func patternPointFor(p Pattern, obj Shape, worldPoint Tuple) Tuple {
-	objInv, _ := obj.GetTransformMatrix().Inverse()
-	objPoint, _ := objInv.MultiplyWithTuple(worldPoint)
-	patInv, _ := p.GetTransform().Inverse()
-	ret, _ := patInv.MultiplyWithTuple(objPoint)
-	return ret
+func patternPointFor(p Pattern, obj Shape, worldPoint Vec4) Vec4 {
+	return p.GetInverse().MultiplyWithVec(worldToObject(obj, worldPoint))
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -23,6 +23,7 @@ func patternPointFor(p Pattern, obj Shape, worldPoint Tuple) Tuple {
 type StripePattern struct {
 	a, b      Color
 	transform Matrix
+	inverse   Mat4
 }
 
 func NewStripePattern(a, b Color) *StripePattern {
@@ -30,14 +31,15 @@ func NewStripePattern(a, b Color) *StripePattern {
 		a:         a,
 		b:         b,
 		transform: IdentityMatrix(),
+		inverse:   Identity4(),
 	}
 }
 
-func (sp *StripePattern) PatternAtObject(obj Shape, point Tuple) Color {
+func (sp *StripePattern) PatternAtObject(obj Shape, point Vec4) Color {
 	return sp.PatternAt(patternPointFor(sp, obj, point))
 }
 
-func (sp *StripePattern) PatternAt(point Tuple) Color {
+func (sp *StripePattern) PatternAt(point Vec4) Color {
 	if int(math.Floor(point[X]))%2 == 0 {
 		return sp.a
 	}
@@ -48,8 +50,17 @@ func (sp *StripePattern) GetTransform() Matrix {
 	return sp.transform
 }
 
-func (sp *StripePattern) SetTransform(m Matrix) {
-	sp.transform = m
+func (sp *StripePattern) GetInverse() Mat4 {
+	return sp.inverse
+}
+
+func (sp *StripePattern) SetTransform(m Matrix) error {
+	inv, _, err := invertTransform(m)
+	if err != nil {
+		return err
+	}
+	sp.transform, sp.inverse = m, inv
+	return nil
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -57,6 +68,7 @@ func (sp *StripePattern) SetTransform(m Matrix) {
 type GradientPattern struct {
 	a, b      Color
 	transform Matrix
+	inverse   Mat4
 }
 
 func NewGradientPattern(a, b Color) *GradientPattern {
@@ -64,14 +76,15 @@ func NewGradientPattern(a, b Color) *GradientPattern {
 		a:         a,
 		b:         b,
 		transform: IdentityMatrix(),
+		inverse:   Identity4(),
 	}
 }
 
-func (gp *GradientPattern) PatternAtObject(obj Shape, point Tuple) Color {
+func (gp *GradientPattern) PatternAtObject(obj Shape, point Vec4) Color {
 	return gp.PatternAt(patternPointFor(gp, obj, point))
 }
 
-func (gp *GradientPattern) PatternAt(point Tuple) Color {
+func (gp *GradientPattern) PatternAt(point Vec4) Color {
 	distance := gp.b.SubtractColor(gp.a)
 	fraction := point[X] - math.Floor(point[X])
 	return gp.a.AddColor(distance.MultiplyByScalar(fraction))
@@ -81,8 +94,17 @@ func (gp *GradientPattern) GetTransform() Matrix {
 	return gp.transform
 }
 
-func (gp *GradientPattern) SetTransform(m Matrix) {
-	gp.transform = m
+func (gp *GradientPattern) GetInverse() Mat4 {
+	return gp.inverse
+}
+
+func (gp *GradientPattern) SetTransform(m Matrix) error {
+	inv, _, err := invertTransform(m)
+	if err != nil {
+		return err
+	}
+	gp.transform, gp.inverse = m, inv
+	return nil
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -90,6 +112,7 @@ func (gp *GradientPattern) SetTransform(m Matrix) {
 type RingPattern struct {
 	a, b      Color
 	transform Matrix
+	inverse   Mat4
 }
 
 func NewRingPattern(a, b Color) *RingPattern {
@@ -97,14 +120,15 @@ func NewRingPattern(a, b Color) *RingPattern {
 		a:         a,
 		b:         b,
 		transform: IdentityMatrix(),
+		inverse:   Identity4(),
 	}
 }
 
-func (rp *RingPattern) PatternAtObject(obj Shape, point Tuple) Color {
+func (rp *RingPattern) PatternAtObject(obj Shape, point Vec4) Color {
 	return rp.PatternAt(patternPointFor(rp, obj, point))
 }
 
-func (rp *RingPattern) PatternAt(point Tuple) Color {
+func (rp *RingPattern) PatternAt(point Vec4) Color {
 	dist := math.Sqrt(point[X]*point[X] + point[Z]*point[Z])
 	if int(math.Floor(dist))%2 == 0 {
 		return rp.a
@@ -116,8 +140,17 @@ func (rp *RingPattern) GetTransform() Matrix {
 	return rp.transform
 }
 
-func (rp *RingPattern) SetTransform(m Matrix) {
-	rp.transform = m
+func (rp *RingPattern) GetInverse() Mat4 {
+	return rp.inverse
+}
+
+func (rp *RingPattern) SetTransform(m Matrix) error {
+	inv, _, err := invertTransform(m)
+	if err != nil {
+		return err
+	}
+	rp.transform, rp.inverse = m, inv
+	return nil
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -125,6 +158,7 @@ func (rp *RingPattern) SetTransform(m Matrix) {
 type Checker3DPattern struct {
 	a, b      Color
 	transform Matrix
+	inverse   Mat4
 }
 
 func NewChecker3DPattern(a, b Color) *Checker3DPattern {
@@ -132,14 +166,15 @@ func NewChecker3DPattern(a, b Color) *Checker3DPattern {
 		a:         a,
 		b:         b,
 		transform: IdentityMatrix(),
+		inverse:   Identity4(),
 	}
 }
 
-func (cp *Checker3DPattern) PatternAtObject(obj Shape, point Tuple) Color {
+func (cp *Checker3DPattern) PatternAtObject(obj Shape, point Vec4) Color {
 	return cp.PatternAt(patternPointFor(cp, obj, point))
 }
 
-func (cp *Checker3DPattern) PatternAt(point Tuple) Color {
+func (cp *Checker3DPattern) PatternAt(point Vec4) Color {
 	sum := int(math.Floor(point[X]) + math.Floor(point[Y]) + math.Floor(point[Z]))
 	if sum%2 == 0 {
 		return cp.a
@@ -151,6 +186,15 @@ func (cp *Checker3DPattern) GetTransform() Matrix {
 	return cp.transform
 }
 
-func (cp *Checker3DPattern) SetTransform(m Matrix) {
-	cp.transform = m
+func (cp *Checker3DPattern) GetInverse() Mat4 {
+	return cp.inverse
+}
+
+func (cp *Checker3DPattern) SetTransform(m Matrix) error {
+	inv, _, err := invertTransform(m)
+	if err != nil {
+		return err
+	}
+	cp.transform, cp.inverse = m, inv
+	return nil
 }
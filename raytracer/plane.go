@@ -5,46 +5,71 @@ import "math"
 const EPSILON float64 = 0.00001
 
 type Plane struct {
-	transform Matrix
-	material  *Material
+	transform        Matrix
+	inverse          Mat4
+	inverseTranspose Mat4
+	material         *Material
+	parent           Shape
 }
 
 func NewPlane() *Plane {
 	return &Plane{
-		transform: IdentityMatrix(),
-		material:  DefaultMaterial(),
+		transform:        IdentityMatrix(),
+		inverse:          Identity4(),
+		inverseTranspose: Identity4(),
+		material:         DefaultMaterial(),
 	}
 }
 
-func (p *Plane) SetTransform(m Matrix) {
-	p.transform = m
+func (p *Plane) SetTransform(m Matrix) error {
+	inv, invT, err := invertTransform(m)
+	if err != nil {
+		return err
+	}
+	p.transform, p.inverse, p.inverseTranspose = m, inv, invT
+	return nil
 }
 
 func (p *Plane) GetTransformMatrix() Matrix {
 	return p.transform
 }
 
+func (p *Plane) GetInverseMatrix() Mat4 {
+	return p.inverse
+}
+
+func (p *Plane) GetInverseTransposeMatrix() Mat4 {
+	return p.inverseTranspose
+}
+
 func (p *Plane) GetMaterial() *Material {
 	return p.material
 }
 
+func (p *Plane) GetParent() Shape {
+	return p.parent
+}
+
+func (p *Plane) setParent(parent Shape) {
+	p.parent = parent
+}
+
 func (p *Plane) NormalAt(worldPoint Tuple) Tuple {
+	return normalAt(p, worldPoint.Vec4(), Intersection{}).Tuple()
+}
 
-	localNormal := NewVector(0, 1, 0)
+func (p *Plane) localNormalAt(objectPoint Vec4, hit Intersection) Vec4 {
+	return Vector4(0, 1, 0)
+}
 
-	//convert back into world space
-	tm, _ := p.GetTransformMatrix().Inverse()
-	tmt, _ := tm.Transpose()
-	worldNormal, _ := tmt.MultiplyWithTuple(localNormal)
-	worldNormal[W] = 0
-	worldNormal, _ = worldNormal.Normalize()
-	return worldNormal
+func (p *Plane) Bounds() BoundingBox {
+	inf := math.Inf(1)
+	return NewBoundingBox(NewPoint(-inf, 0, -inf), NewPoint(inf, 0, inf))
 }
 
 func (p *Plane) Intersect(r Ray) []Intersection {
 	//Convert ray into object space
-	inv, _ := p.transform.Inverse()
-	localRay := r.Transform(inv)
+	localRay := r.transform(p.inverse)
 
 	if math.Abs(localRay.direction[Y]) < EPSILON {
 		return nil
@@ -0,0 +1,86 @@
+package raytracer
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+)
+
+// A *Canvas is a draw.Image. The canvas stores linear colours, while the
+// image packages (and every PNG viewer) expect sRGB, so At encodes each
+// pixel with the sRGB transfer function and Set decodes it again.
+
+func (c *Canvas) ColorModel() color.Model {
+	return color.RGBA64Model
+}
+
+func (c *Canvas) Bounds() image.Rectangle {
+	return image.Rect(0, 0, c.width, c.Height)
+}
+
+// At returns the pixel at (x, y) as an opaque sRGB color, or transparent
+// black when it is off the canvas.
+func (c *Canvas) At(x, y int) color.Color {
+	if !(image.Point{x, y}.In(c.Bounds())) {
+		return color.RGBA64{}
+	}
+	p := c.pixels[y][x]
+	return color.RGBA64{
+		R: uint16(math.Round(encodeSRGB(p[R]) * 0xffff)),
+		G: uint16(math.Round(encodeSRGB(p[G]) * 0xffff)),
+		B: uint16(math.Round(encodeSRGB(p[B]) * 0xffff)),
+		A: 0xffff,
+	}
+}
+
+// Set writes an sRGB color to (x, y). The canvas has no alpha channel, so a
+// translucent color is stored as if composited over black.
+func (c *Canvas) Set(x, y int, col color.Color) {
+	r, g, b, _ := col.RGBA()
+	c.WritePixel(x, y, NewColor(
+		decodeSRGB(float64(r)/0xffff),
+		decodeSRGB(float64(g)/0xffff),
+		decodeSRGB(float64(b)/0xffff)))
+}
+
+// Image returns an 8-bit sRGB copy of the canvas.
+func (c *Canvas) Image() *image.RGBA {
+	img := image.NewRGBA(c.Bounds())
+	for y := 0; y < c.Height; y++ {
+		for x := 0; x < c.width; x++ {
+			p := c.pixels[y][x]
+			img.SetRGBA(x, y, color.RGBA{
+				R: uint8(math.Round(encodeSRGB(p[R]) * 255)),
+				G: uint8(math.Round(encodeSRGB(p[G]) * 255)),
+				B: uint8(math.Round(encodeSRGB(p[B]) * 255)),
+				A: 255,
+			})
+		}
+	}
+	return img
+}
+
+// CanvasFromImage converts img to a canvas of linear colours, moving its
+// top-left corner to (0, 0).
+func CanvasFromImage(img image.Image) Canvas {
+	bounds := img.Bounds()
+	canvas := NewCanvas(bounds.Dx(), bounds.Dy())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			canvas.Set(x-bounds.Min.X, y-bounds.Min.Y, img.At(x, y))
+		}
+	}
+	return canvas
+}
+
+// CanvasToPNG writes the canvas to filename as an 8-bit sRGB PNG.
+func (c *Canvas) CanvasToPNG(filename string) error {
+	return writeFile(filename, c.WritePNG)
+}
+
+// WritePNG writes the canvas to w as an 8-bit sRGB PNG.
+func (c *Canvas) WritePNG(w io.Writer) error {
+	return png.Encode(w, c.Image())
+}
@@ -0,0 +1,239 @@
+package raytracer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// ppmLineLength is the longest line an ASCII PPM may contain.
+const ppmLineLength = 70
+
+// CanvasToPPM writes the canvas to filename as an ASCII (P3) PPM.
+func (c *Canvas) CanvasToPPM(filename string) error {
+	return writeFile(filename, c.WritePPM)
+}
+
+// CanvasToPPMBinary writes the canvas to filename as a binary (P6) PPM.
+func (c *Canvas) CanvasToPPMBinary(filename string) error {
+	return writeFile(filename, c.WritePPMBinary)
+}
+
+// writeFile creates filename and passes it to write.
+func writeFile(filename string, write func(io.Writer) error) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// WritePPM writes the canvas to w as an ASCII (P3) PPM with a maximum value
+// of 255, keeping every line within 70 characters.
+func (c *Canvas) WritePPM(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "P3\n%d %d\n255\n", c.width, c.Height)
+
+	var buf []byte
+	for i := 0; i < c.Height; i++ {
+		lineLength := 0
+		for j := 0; j < c.width; j++ {
+			r, g, b := colorTo8Bit(c.pixels[i][j])
+			for _, v := range [3]uint8{r, g, b} {
+				buf = strconv.AppendUint(buf[:0], uint64(v), 10)
+				if lineLength > 0 && lineLength+len(buf)+1 > ppmLineLength {
+					out.WriteByte('\n')
+					lineLength = 0
+				}
+				if lineLength > 0 {
+					out.WriteByte(' ')
+					lineLength++
+				}
+				out.Write(buf)
+				lineLength += len(buf)
+			}
+		}
+		out.WriteByte('\n')
+	}
+	return out.Flush()
+}
+
+// WritePPMBinary writes the canvas to w as a binary (P6) PPM with a maximum
+// value of 255. It is a fraction of the size of WritePPM's output.
+func (c *Canvas) WritePPMBinary(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "P6\n%d %d\n255\n", c.width, c.Height)
+
+	for i := 0; i < c.Height; i++ {
+		for j := 0; j < c.width; j++ {
+			r, g, b := colorTo8Bit(c.pixels[i][j])
+			out.Write([]byte{r, g, b})
+		}
+	}
+	return out.Flush()
+}
+
+// colorTo8Bit clamps each channel of color to 0-1 and scales it to 0-255.
+func colorTo8Bit(color Color) (uint8, uint8, uint8) {
+	return uint8(clamp(color[R]*255, 0, 255)),
+		uint8(clamp(color[G]*255, 0, 255)),
+		uint8(clamp(color[B]*255, 0, 255))
+}
+
+// CanvasFromPPMFile opens filename and reads it with CanvasFromPPM.
+func CanvasFromPPMFile(filename string) (Canvas, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return Canvas{}, err
+	}
+	defer file.Close()
+	return CanvasFromPPM(file)
+}
+
+// CanvasFromPPM reads an ASCII (P3) or binary (P6) PPM from r. Comments may
+// appear anywhere in the header, and in the data of a P3 file. Samples are
+// divided by the file's maximum value, so every channel is between 0 and 1.
+// A P6 file with a maximum value above 255 stores each sample in two bytes,
+// most significant first.
+func CanvasFromPPM(r io.Reader) (Canvas, error) {
+	p := &ppmReader{r: bufio.NewReader(r)}
+
+	magic, err := p.token()
+	if err != nil {
+		return Canvas{}, err
+	}
+	if magic != "P3" && magic != "P6" {
+		return Canvas{}, fmt.Errorf("ppm: unsupported format %q, expected P3 or P6", magic)
+	}
+
+	width, err := p.headerInt("width", 1, 1<<16)
+	if err != nil {
+		return Canvas{}, err
+	}
+	height, err := p.headerInt("height", 1, 1<<16)
+	if err != nil {
+		return Canvas{}, err
+	}
+	maxval, err := p.headerInt("maximum value", 1, 65535)
+	if err != nil {
+		return Canvas{}, err
+	}
+
+	sample := p.asciiSample
+	if magic == "P6" {
+		// token has already consumed the single whitespace character that
+		// separates the header from the data
+		sample = p.binarySample
+	}
+
+	// the canvas grows a row at a time as the data arrives, so a header that
+	// claims a huge image cannot make us allocate more than the file holds
+	pixels := make([][]Color, 0, min(height, 1024))
+	scale := 1 / float64(maxval)
+	for y := 0; y < height; y++ {
+		row := make([]Color, width)
+		for x := range row {
+			var rgb [3]float64
+			for i := range rgb {
+				v, err := sample(maxval)
+				if err != nil {
+					return Canvas{}, fmt.Errorf("ppm: pixel (%d, %d): %w", x, y, err)
+				}
+				rgb[i] = float64(v) * scale
+			}
+			row[x] = NewColor(rgb[0], rgb[1], rgb[2])
+		}
+		pixels = append(pixels, row)
+	}
+	return Canvas{width, height, pixels}, nil
+}
+
+type ppmReader struct {
+	r *bufio.Reader
+}
+
+// token returns the next whitespace-separated token, skipping comments.
+func (p *ppmReader) token() (string, error) {
+	var tok []byte
+	for {
+		b, err := p.r.ReadByte()
+		if err == io.EOF {
+			if len(tok) > 0 {
+				return string(tok), nil
+			}
+			return "", io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch {
+		case b == '#':
+			if _, err := p.r.ReadString('\n'); err != nil && err != io.EOF {
+				return "", err
+			}
+			if len(tok) > 0 {
+				return string(tok), nil
+			}
+		case b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\v' || b == '\f':
+			if len(tok) > 0 {
+				return string(tok), nil
+			}
+		default:
+			tok = append(tok, b)
+		}
+	}
+}
+
+// headerInt reads a header field, which must be an integer in [min, max].
+func (p *ppmReader) headerInt(name string, min, max int) (int, error) {
+	tok, err := p.token()
+	if err != nil {
+		return 0, fmt.Errorf("ppm: missing %s", name)
+	}
+	v, err := strconv.Atoi(tok)
+	if err != nil || v < min || v > max {
+		return 0, fmt.Errorf("ppm: invalid %s %q", name, tok)
+	}
+	return v, nil
+}
+
+func (p *ppmReader) asciiSample(maxval int) (int, error) {
+	tok, err := p.token()
+	if err != nil {
+		return 0, fmt.Errorf("unexpected end of data")
+	}
+	v, err := strconv.Atoi(tok)
+	if err != nil {
+		return 0, fmt.Errorf("invalid sample %q", tok)
+	}
+	if v < 0 || v > maxval {
+		return 0, fmt.Errorf("sample %d is outside 0-%d", v, maxval)
+	}
+	return v, nil
+}
+
+func (p *ppmReader) binarySample(maxval int) (int, error) {
+	hi, err := p.r.ReadByte()
+	if err != nil {
+		return 0, fmt.Errorf("unexpected end of data")
+	}
+	v := int(hi)
+	if maxval > 255 {
+		lo, err := p.r.ReadByte()
+		if err != nil {
+			return 0, fmt.Errorf("unexpected end of data")
+		}
+		v = v<<8 | int(lo)
+	}
+	if v > maxval {
+		return 0, fmt.Errorf("sample %d is outside 0-%d", v, maxval)
+	}
+	return v, nil
+}
@@ -1,33 +1,96 @@
 package raytracer
 
-import "math"
+import (
+	"cmp"
+	"math"
+	"slices"
+)
 
 type Ray struct {
-	origin    Tuple
-	direction Tuple
+	origin    Vec4
+	direction Vec4
 }
 
 type Shape interface {
 	GetTransformMatrix() Matrix
+	// SetTransform sets the shape's transform and caches its inverse. It
+	// returns an error, leaving the shape unchanged, when m cannot be
+	// inverted.
+	SetTransform(m Matrix) error
 	NormalAt(x Tuple) Tuple
 	GetMaterial() *Material
+	SetMaterial(material *Material)
 	Intersect(ray Ray) []Intersection
+	GetInverseMatrix() Mat4
+	GetInverseTransposeMatrix() Mat4
+	GetParent() Shape
+	// Bounds returns the shape's bounding box in object space.
+	Bounds() BoundingBox
+
+	setParent(parent Shape)
+	localNormalAt(objectPoint Vec4, hit Intersection) Vec4
+}
+
+// WorldToObject converts a world-space point into the object space of s,
+// first passing it through the transforms of every group or CSG s is nested in.
+func WorldToObject(s Shape, worldPoint Tuple) Tuple {
+	return worldToObject(s, worldPoint.Vec4()).Tuple()
+}
+
+func worldToObject(s Shape, worldPoint Vec4) Vec4 {
+	if parent := s.GetParent(); parent != nil {
+		worldPoint = worldToObject(parent, worldPoint)
+	}
+	return s.GetInverseMatrix().MultiplyWithVec(worldPoint)
+}
+
+// NormalToWorld converts an object-space normal of s back into world space,
+// walking up through its parents.
+func NormalToWorld(s Shape, objectNormal Tuple) Tuple {
+	return normalToWorld(s, objectNormal.Vec4()).Tuple()
+}
+
+func normalToWorld(s Shape, objectNormal Vec4) Vec4 {
+	normal := s.GetInverseTransposeMatrix().MultiplyWithVec(objectNormal)
+	normal[W] = 0
+	normal = normal.Normalize()
+
+	if parent := s.GetParent(); parent != nil {
+		normal = normalToWorld(parent, normal)
+	}
+	return normal
 }
 
+// normalAt is the shared NormalAt for every shape: the shape only has to
+// know its normal in its own object space. The hit is passed through for
+// shapes such as SmoothTriangle that interpolate their normal from it.
+func normalAt(s Shape, worldPoint Vec4, hit Intersection) Vec4 {
+	objectPoint := worldToObject(s, worldPoint)
+	objectNormal := s.localNormalAt(objectPoint, hit)
+	return normalToWorld(s, objectNormal)
+}
+
+// Intersection records where a ray hit a shape. u and v are the barycentric
+// coordinates of the hit on a triangle and are zero for every other shape.
 type Intersection struct {
 	t float64
 	o Shape
+	u float64
+	v float64
 }
 
 type Computation struct {
-	t         float64
-	o         Shape
-	point     Tuple
-	eyev      Tuple
-	normalv   Tuple
-	inside    bool
-	overpoint Tuple
-	reflectv  Tuple
+	t          float64
+	o          Shape
+	point      Vec4
+	eyev       Vec4
+	normalv    Vec4
+	inside     bool
+	overpoint  Vec4
+	underpoint Vec4
+	reflectv   Vec4
+	n1         float64
+	n2         float64
 }
 
 func (i Intersection) GetTime() float64 {
@@ -38,52 +101,138 @@ func (i Intersection) GetObject() Shape {
 	return i.o
 }
 
+func (i Intersection) GetU() float64 {
+	return i.u
+}
+
+func (i Intersection) GetV() float64 {
+	return i.v
+}
+
 func NewRay(origin, direction Tuple) Ray {
-	return Ray{origin: origin, direction: direction}
+	return Ray{origin: origin.Vec4(), direction: direction.Vec4()}
 }
 
 func NewIntersection(T float64, o Shape) Intersection {
 	return Intersection{t: T, o: o}
 }
 
+func NewIntersectionWithUV(T float64, o Shape, u, v float64) Intersection {
+	return Intersection{t: T, o: o, u: u, v: v}
+}
+
 func (r Ray) Position(t float64) (Tuple, error) {
-	return r.origin.Add(r.direction.Multiply(t))
+	return r.at(t).Tuple(), nil
+}
+
+func (r Ray) at(t float64) Vec4 {
+	return r.origin.Add(r.direction.Scale(t))
 }
 
 func Intersections(args ...Intersection) []Intersection {
 	return args
 }
 
-func PrepareComputations(intersection Intersection, ray Ray) Computation {
+// sortIntersections sorts xs by t. Unlike sort.Slice it does not allocate,
+// which matters as it runs for every ray.
+func sortIntersections(xs []Intersection) {
+	slices.SortFunc(xs, func(a, b Intersection) int {
+		return cmp.Compare(a.t, b.t)
+	})
+}
+
+// PrepareComputations precomputes the state needed to shade a hit. xs is the
+// full, sorted list of intersections the hit was taken from; it is used to work
+// out which materials the ray is leaving (n1) and entering (n2). When xs is
+// omitted the hit is treated as the only intersection.
+func PrepareComputations(intersection Intersection, ray Ray, xs ...Intersection) Computation {
 	epsilon := 0.00001
 
 	comps := Computation{}
 	comps.t = intersection.t
 	comps.o = intersection.o
-	r, _ := ray.Position(comps.t)
-	comps.point = r
-	eye := ray.Direction().Multiply(-1)
-	comps.eyev = eye
-
-	normal := comps.o.NormalAt(comps.point)
-	comps.normalv = normal
+	comps.point = ray.at(comps.t)
+	comps.eyev = ray.direction.Negate()
+	comps.normalv = normalAt(comps.o, comps.point, intersection)
 
-	dot, _ := Dot(comps.normalv, comps.eyev)
-	if dot < 0 {
+	if comps.normalv.Dot(comps.eyev) < 0 {
 		comps.inside = true
-		comps.normalv = comps.normalv.Multiply(-1)
+		comps.normalv = comps.normalv.Negate()
 	} else {
 		comps.inside = false
 	}
 
-	add, _ := comps.point.Add(comps.normalv.Multiply(epsilon))
-	comps.overpoint = add
+	comps.overpoint = comps.point.Add(comps.normalv.Scale(epsilon))
+	comps.underpoint = comps.point.Sub(comps.normalv.Scale(epsilon))
+	comps.reflectv = ray.direction.Reflect(comps.normalv)
 
-	reflectv, _ := Reflect(ray.Direction(), comps.normalv)
-	comps.reflectv = reflectv
+	if len(xs) == 0 {
+		// the ray leaves empty space and enters the hit object
+		comps.n1, comps.n2 = 1.0, comps.o.GetMaterial().refractiveIndex
+	} else {
+		comps.n1, comps.n2 = refractiveIndices(intersection, xs)
+	}
 	return comps
 }
 
+// refractiveIndices walks the intersections in order, tracking which objects
+// the ray is currently inside of. When the hit is reached, the last object in
+// the list before the hit gives n1 and the last object after it gives n2.
+func refractiveIndices(hit Intersection, xs []Intersection) (float64, float64) {
+	n1, n2 := 1.0, 1.0
+	// rays are rarely inside more than a few objects at once, so the list
+	// starts out on the stack
+	var buf [8]Shape
+	containers := buf[:0]
+
+	for _, i := range xs {
+		isHit := i == hit
+		if isHit && len(containers) > 0 {
+			n1 = containers[len(containers)-1].GetMaterial().refractiveIndex
+		}
+
+		index := -1
+		for j, c := range containers {
+			if c == i.o {
+				index = j
+				break
+			}
+		}
+		if index >= 0 {
+			containers = append(containers[:index], containers[index+1:]...)
+		} else {
+			containers = append(containers, i.o)
+		}
+
+		if isHit {
+			if len(containers) > 0 {
+				n2 = containers[len(containers)-1].GetMaterial().refractiveIndex
+			}
+			break
+		}
+	}
+	return n1, n2
+}
+
+// Schlick approximates the Fresnel reflectance at the hit, returning the
+// fraction of light that is reflected rather than refracted.
+func Schlick(comps Computation) float64 {
+	cos := comps.eyev.Dot(comps.normalv)
+
+	if comps.n1 > comps.n2 {
+		n := comps.n1 / comps.n2
+		sin2t := n * n * (1.0 - cos*cos)
+		if sin2t > 1.0 {
+			return 1.0
+		}
+		cos = math.Sqrt(1.0 - sin2t)
+	}
+
+	r0 := (comps.n1 - comps.n2) / (comps.n1 + comps.n2)
+	r0 = r0 * r0
+	return r0 + (1-r0)*math.Pow(1-cos, 5)
+}
+
 func Hit(args []Intersection) *Intersection {
 	currentMin := math.Inf(1)
 	var returnIntersection *Intersection = nil
@@ -98,18 +247,24 @@ func Hit(args []Intersection) *Intersection {
 
 // Transform applies the given matrix to the ray, returning a new ray.
 // This is used to convert a world-space ray into object space
-// by applying the inverse of the object's transform matrix.
+// by applying the inverse of the object's transform matrix. It returns an
+// error when m is not a 4x4 matrix.
+func (r Ray) Transform(m Matrix) (Ray, error) {
+	m4, err := m.Mat4()
+	if err != nil {
+		return Ray{}, err
+	}
+	return r.transform(m4), nil
+}
 
-func (r Ray) Transform(m Matrix) Ray {
-	origin, _ := m.MultiplyWithTuple(r.origin)
-	direction, _ := m.MultiplyWithTuple(r.direction)
-	return NewRay(origin, direction)
+func (r Ray) transform(m Mat4) Ray {
+	return Ray{origin: m.MultiplyWithVec(r.origin), direction: m.MultiplyWithVec(r.direction)}
 }
 
 func (r Ray) Origin() Tuple {
-	return r.origin
+	return r.origin.Tuple()
 }
 
 func (r Ray) Direction() Tuple {
-	return r.direction
+	return r.direction.Tuple()
 }
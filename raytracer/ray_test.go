@@ -268,15 +268,18 @@ func TestRayTransform(t *testing.T) {
 		if err != nil {
 			t.Fatalf("failed to create translation matrix: %v", err)
 		}
-		r2 := r.Transform(m)
+		r2, err := r.Transform(m)
+		if err != nil {
+			t.Fatalf("failed to transform ray: %v", err)
+		}
 
 		expectedOrigin := NewPoint(4, 6, 8)
 		expectedDirection := NewVector(0, 1, 0)
 
-		if !r2.origin.Equals(expectedOrigin) {
+		if !r2.origin.Equals(expectedOrigin.Vec4()) {
 			t.Errorf("expected origin %v, got %v", expectedOrigin, r2.origin)
 		}
-		if !r2.direction.Equals(expectedDirection) {
+		if !r2.direction.Equals(expectedDirection.Vec4()) {
 			t.Errorf("expected direction %v, got %v", expectedDirection, r2.direction)
 		}
 	})
@@ -287,18 +290,27 @@ func TestRayTransform(t *testing.T) {
 		if err != nil {
 			t.Fatalf("failed to create scaling matrix: %v", err)
 		}
-		r2 := r.Transform(m)
+		r2, err := r.Transform(m)
+		if err != nil {
+			t.Fatalf("failed to transform ray: %v", err)
+		}
 
 		expectedOrigin := NewPoint(2, 6, 12)
 		expectedDirection := NewVector(0, 3, 0)
 
-		if !r2.origin.Equals(expectedOrigin) {
+		if !r2.origin.Equals(expectedOrigin.Vec4()) {
 			t.Errorf("expected origin %v, got %v", expectedOrigin, r2.origin)
 		}
-		if !r2.direction.Equals(expectedDirection) {
+		if !r2.direction.Equals(expectedDirection.Vec4()) {
 			t.Errorf("expected direction %v, got %v", expectedDirection, r2.direction)
 		}
 	})
+	t.Run("Transforming a ray by a matrix that is not 4x4", func(t *testing.T) {
+		r := NewRay(NewPoint(1, 2, 3), NewVector(0, 1, 0))
+		if _, err := r.Transform(NewMatrix(3, 3)); err == nil {
+			t.Errorf("expected an error for a 3x3 matrix")
+		}
+	})
 }
 
 func TestRaySphereTransformIntersection(t *testing.T) {
@@ -359,17 +371,17 @@ func TestPrepareComputations(t *testing.T) {
 		}
 
 		expectedPoint := NewPoint(0, 0, -1)
-		if !comps.point.Equals(expectedPoint) {
+		if !comps.point.Equals(expectedPoint.Vec4()) {
 			t.Errorf("Expected comps.point = %v, got %v", expectedPoint, comps.point)
 		}
 
 		expectedEyeV := NewVector(0, 0, -1)
-		if !comps.eyev.Equals(expectedEyeV) {
+		if !comps.eyev.Equals(expectedEyeV.Vec4()) {
 			t.Errorf("Expected comps.eyev = %v, got %v", expectedEyeV, comps.eyev)
 		}
 
 		expectedNormalV := NewVector(0, 0, -1)
-		if !comps.normalv.Equals(expectedNormalV) {
+		if !comps.normalv.Equals(expectedNormalV.Vec4()) {
 			t.Errorf("Expected comps.normalv = %v, got %v", expectedNormalV, comps.normalv)
 		}
 	})
@@ -407,12 +419,12 @@ func TestPrepareComputations(t *testing.T) {
 		comps := PrepareComputations(i, r)
 
 		expectedPoint := NewPoint(0, 0, 1)
-		if !comps.point.Equals(expectedPoint) {
+		if !comps.point.Equals(expectedPoint.Vec4()) {
 			t.Errorf("Expected comps.point = %v, got %v", expectedPoint, comps.point)
 		}
 
 		expectedEyeV := NewVector(0, 0, -1)
-		if !comps.eyev.Equals(expectedEyeV) {
+		if !comps.eyev.Equals(expectedEyeV.Vec4()) {
 			t.Errorf("Expected comps.eyev = %v, got %v", expectedEyeV, comps.eyev)
 		}
 
@@ -421,7 +433,7 @@ func TestPrepareComputations(t *testing.T) {
 		}
 
 		expectedNormalV := NewVector(0, 0, -1)
-		if !comps.normalv.Equals(expectedNormalV) {
+		if !comps.normalv.Equals(expectedNormalV.Vec4()) {
 			t.Errorf("Expected comps.normalv = %v, got %v", expectedNormalV, comps.normalv)
 		}
 	})
@@ -433,7 +445,7 @@ func TestWorldRay(t *testing.T) {
 		w.DefaultWorld()
 
 		r := NewRay(NewPoint(0, 0, -5), NewVector(0, 1, 0)) // ray goes up, misses both spheres
-		c := w.ColorAt(r)
+		c := w.ColorAt(r, 4)
 
 		expected := NewColor(0, 0, 0)
 		if !c.Equals(expected) {
@@ -446,7 +458,7 @@ func TestWorldRay(t *testing.T) {
 		w.DefaultWorld()
 
 		r := NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1)) // ray hits the first sphere
-		c := w.ColorAt(r)
+		c := w.ColorAt(r, 4)
 
 		expected := NewColor(0.38066, 0.047583, 0.2855)
 		if !c.Equals(expected) {
@@ -467,7 +479,7 @@ func TestWorldRay(t *testing.T) {
 		inner.GetMaterial().ambient = 1
 
 		r := NewRay(NewPoint(0, 0, 0.75), NewVector(0, 0, -1)) // intersects both, inner closer
-		c := w.ColorAt(r)
+		c := w.ColorAt(r, 4)
 
 		expected := inner.GetMaterial().color
 		if !c.Equals(expected) {
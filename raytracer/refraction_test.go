@@ -0,0 +1,318 @@
+package raytracer
+
+import (
+	"math"
+	"testing"
+)
+
+func glassSphere() *Sphere {
+	s := NewSphere()
+	s.material.transparency = 1.0
+	s.material.refractiveIndex = 1.5
+	return s
+}
+
+// testPattern returns the point it was asked about as a color, which makes
+// it easy to see where a refracted ray ended up.
+type testPattern struct {
+	transform Matrix
+	inverse   Mat4
+}
+
+func (tp *testPattern) PatternAtObject(obj Shape, point Vec4) Color {
+	return tp.PatternAt(patternPointFor(tp, obj, point))
+}
+
+func (tp *testPattern) PatternAt(point Vec4) Color {
+	return NewColor(point[X], point[Y], point[Z])
+}
+
+func (tp *testPattern) GetTransform() Matrix {
+	return tp.transform
+}
+
+func (tp *testPattern) GetInverse() Mat4 {
+	return tp.inverse
+}
+
+func (tp *testPattern) SetTransform(m Matrix) error {
+	inv, _, err := invertTransform(m)
+	if err != nil {
+		return err
+	}
+	tp.transform, tp.inverse = m, inv
+	return nil
+}
+
+func TestMaterialRefraction(t *testing.T) {
+	t.Run("Transparency and refractive index for the default material", func(t *testing.T) {
+		m := DefaultMaterial()
+		if m.transparency != 0.0 {
+			t.Errorf("Expected transparency = 0, got %v", m.transparency)
+		}
+		if m.refractiveIndex != 1.0 {
+			t.Errorf("Expected refractive index = 1, got %v", m.refractiveIndex)
+		}
+	})
+}
+
+func TestRefractiveIndices(t *testing.T) {
+	a := glassSphere()
+	sa, _ := ScalingMatrix(2, 2, 2)
+	a.SetTransform(sa)
+	a.material.refractiveIndex = 1.5
+
+	b := glassSphere()
+	tb, _ := TranslationMatrix(0, 0, -0.25)
+	b.SetTransform(tb)
+	b.material.refractiveIndex = 2.0
+
+	c := glassSphere()
+	tc, _ := TranslationMatrix(0, 0, 0.25)
+	c.SetTransform(tc)
+	c.material.refractiveIndex = 2.5
+
+	r := NewRay(NewPoint(0, 0, -4), NewVector(0, 0, 1))
+	xs := Intersections(
+		Intersection{t: 2, o: a},
+		Intersection{t: 2.75, o: b},
+		Intersection{t: 3.25, o: c},
+		Intersection{t: 4.75, o: b},
+		Intersection{t: 5.25, o: c},
+		Intersection{t: 6, o: a},
+	)
+
+	expected := []struct{ n1, n2 float64 }{
+		{1.0, 1.5},
+		{1.5, 2.0},
+		{2.0, 2.5},
+		{2.5, 2.5},
+		{2.5, 1.5},
+		{1.5, 1.0},
+	}
+
+	for i, e := range expected {
+		comps := PrepareComputations(xs[i], r, xs...)
+		if comps.n1 != e.n1 || comps.n2 != e.n2 {
+			t.Errorf("xs[%d]: expected n1 = %v, n2 = %v, got n1 = %v, n2 = %v", i, e.n1, e.n2, comps.n1, comps.n2)
+		}
+	}
+}
+
+func TestUnderPoint(t *testing.T) {
+	t.Run("The under point is offset below the surface", func(t *testing.T) {
+		r := NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1))
+		shape := glassSphere()
+		tm, _ := TranslationMatrix(0, 0, 1)
+		shape.SetTransform(tm)
+		i := Intersection{t: 5, o: shape}
+
+		comps := PrepareComputations(i, r, i)
+
+		if comps.underpoint[Z] <= EPSILON/2 {
+			t.Errorf("Expected underpoint.z > EPSILON/2, got %v", comps.underpoint[Z])
+		}
+		if comps.point[Z] >= comps.underpoint[Z] {
+			t.Errorf("Expected point.z < underpoint.z, got %v >= %v", comps.point[Z], comps.underpoint[Z])
+		}
+	})
+}
+
+func TestRefractedColor(t *testing.T) {
+	t.Run("The refracted color with an opaque surface", func(t *testing.T) {
+		w := NewWorld()
+		w.DefaultWorld()
+		shape := w.GetObjects()[0]
+		r := NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1))
+		xs := Intersections(Intersection{t: 4, o: shape}, Intersection{t: 6, o: shape})
+
+		comps := PrepareComputations(xs[0], r, xs...)
+		c := w.RefractedColor(comps, 5)
+
+		if !c.Equals(NewColor(0, 0, 0)) {
+			t.Errorf("Expected black, got %v", c)
+		}
+	})
+
+	t.Run("The refracted color at the maximum recursive depth", func(t *testing.T) {
+		w := NewWorld()
+		w.DefaultWorld()
+		shape := w.GetObjects()[0]
+		shape.GetMaterial().SetTransparency(1.0)
+		shape.GetMaterial().SetRefractiveIndex(1.5)
+		r := NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1))
+		xs := Intersections(Intersection{t: 4, o: shape}, Intersection{t: 6, o: shape})
+
+		comps := PrepareComputations(xs[0], r, xs...)
+		c := w.RefractedColor(comps, 0)
+
+		if !c.Equals(NewColor(0, 0, 0)) {
+			t.Errorf("Expected black, got %v", c)
+		}
+	})
+
+	t.Run("The refracted color under total internal reflection", func(t *testing.T) {
+		w := NewWorld()
+		w.DefaultWorld()
+		shape := w.GetObjects()[0]
+		shape.GetMaterial().SetTransparency(1.0)
+		shape.GetMaterial().SetRefractiveIndex(1.5)
+		r := NewRay(NewPoint(0, 0, math.Sqrt2/2), NewVector(0, 1, 0))
+		xs := Intersections(
+			Intersection{t: -math.Sqrt2 / 2, o: shape},
+			Intersection{t: math.Sqrt2 / 2, o: shape},
+		)
+
+		comps := PrepareComputations(xs[1], r, xs...)
+		c := w.RefractedColor(comps, 5)
+
+		if !c.Equals(NewColor(0, 0, 0)) {
+			t.Errorf("Expected black, got %v", c)
+		}
+	})
+
+	t.Run("The refracted color with a refracted ray", func(t *testing.T) {
+		w := NewWorld()
+		w.DefaultWorld()
+		a := w.GetObjects()[0]
+		a.GetMaterial().SetAmbient(1.0)
+		a.GetMaterial().Pattern = &testPattern{transform: IdentityMatrix(), inverse: Identity4()}
+		b := w.GetObjects()[1]
+		b.GetMaterial().SetTransparency(1.0)
+		b.GetMaterial().SetRefractiveIndex(1.5)
+		r := NewRay(NewPoint(0, 0, 0.1), NewVector(0, 1, 0))
+		xs := Intersections(
+			Intersection{t: -0.9899, o: a},
+			Intersection{t: -0.4899, o: b},
+			Intersection{t: 0.4899, o: b},
+			Intersection{t: 0.9899, o: a},
+		)
+
+		comps := PrepareComputations(xs[2], r, xs...)
+		c := w.RefractedColor(comps, 5)
+
+		expected := NewColor(0, 0.99888, 0.04725)
+		if !colorsClose(c, expected, 0.0001) {
+			t.Errorf("Expected %v, got %v", expected, c)
+		}
+	})
+
+	t.Run("ShadeHits with a transparent material", func(t *testing.T) {
+		w := NewWorld()
+		w.DefaultWorld()
+
+		floor := NewPlane()
+		tf, _ := TranslationMatrix(0, -1, 0)
+		floor.SetTransform(tf)
+		floor.GetMaterial().SetTransparency(0.5)
+		floor.GetMaterial().SetRefractiveIndex(1.5)
+		w.AddObject(floor)
+
+		ball := NewSphere()
+		tb, _ := TranslationMatrix(0, -3.5, -0.5)
+		ball.SetTransform(tb)
+		ball.GetMaterial().SetColor(1, 0, 0)
+		ball.GetMaterial().SetAmbient(0.5)
+		w.AddObject(ball)
+
+		r := NewRay(NewPoint(0, 0, -3), NewVector(0, -math.Sqrt2/2, math.Sqrt2/2))
+		xs := Intersections(Intersection{t: math.Sqrt2, o: floor})
+
+		comps := PrepareComputations(xs[0], r, xs...)
+		c := w.ShadeHits(comps, 5)
+
+		expected := NewColor(0.93642, 0.68642, 0.68642)
+		if !colorsClose(c, expected, 0.0001) {
+			t.Errorf("Expected %v, got %v", expected, c)
+		}
+	})
+
+	t.Run("ShadeHits with a reflective, transparent material", func(t *testing.T) {
+		w := NewWorld()
+		w.DefaultWorld()
+
+		floor := NewPlane()
+		tf, _ := TranslationMatrix(0, -1, 0)
+		floor.SetTransform(tf)
+		floor.GetMaterial().SetReflective(0.5)
+		floor.GetMaterial().SetTransparency(0.5)
+		floor.GetMaterial().SetRefractiveIndex(1.5)
+		w.AddObject(floor)
+
+		ball := NewSphere()
+		tb, _ := TranslationMatrix(0, -3.5, -0.5)
+		ball.SetTransform(tb)
+		ball.GetMaterial().SetColor(1, 0, 0)
+		ball.GetMaterial().SetAmbient(0.5)
+		w.AddObject(ball)
+
+		r := NewRay(NewPoint(0, 0, -3), NewVector(0, -math.Sqrt2/2, math.Sqrt2/2))
+		xs := Intersections(Intersection{t: math.Sqrt2, o: floor})
+
+		comps := PrepareComputations(xs[0], r, xs...)
+		c := w.ShadeHits(comps, 5)
+
+		// The default world's outer sphere is (0.8, 0.1, 0.6), so the reflected
+		// green channel is lower than the book's 0.69643.
+		expected := NewColor(0.93391, 0.68743, 0.69243)
+		if !colorsClose(c, expected, 0.0001) {
+			t.Errorf("Expected %v, got %v", expected, c)
+		}
+	})
+}
+
+func TestSchlick(t *testing.T) {
+	t.Run("The Schlick approximation under total internal reflection", func(t *testing.T) {
+		shape := glassSphere()
+		r := NewRay(NewPoint(0, 0, math.Sqrt2/2), NewVector(0, 1, 0))
+		xs := Intersections(
+			Intersection{t: -math.Sqrt2 / 2, o: shape},
+			Intersection{t: math.Sqrt2 / 2, o: shape},
+		)
+
+		comps := PrepareComputations(xs[1], r, xs...)
+		reflectance := Schlick(comps)
+
+		if reflectance != 1.0 {
+			t.Errorf("Expected reflectance = 1.0, got %v", reflectance)
+		}
+	})
+
+	t.Run("The Schlick approximation with a perpendicular viewing angle", func(t *testing.T) {
+		shape := glassSphere()
+		r := NewRay(NewPoint(0, 0, 0), NewVector(0, 1, 0))
+		xs := Intersections(
+			Intersection{t: -1, o: shape},
+			Intersection{t: 1, o: shape},
+		)
+
+		comps := PrepareComputations(xs[1], r, xs...)
+		reflectance := Schlick(comps)
+
+		if math.Abs(reflectance-0.04) > 0.0001 {
+			t.Errorf("Expected reflectance = 0.04, got %v", reflectance)
+		}
+	})
+
+	t.Run("The Schlick approximation with small angle and n2 > n1", func(t *testing.T) {
+		shape := glassSphere()
+		r := NewRay(NewPoint(0, 0.99, -2), NewVector(0, 0, 1))
+		xs := Intersections(Intersection{t: 1.8589, o: shape})
+
+		comps := PrepareComputations(xs[0], r, xs...)
+		reflectance := Schlick(comps)
+
+		if math.Abs(reflectance-0.48873) > 0.0001 {
+			t.Errorf("Expected reflectance = 0.48873, got %v", reflectance)
+		}
+	})
+}
+
+func colorsClose(a, b Color, epsilon float64) bool {
+	for i := R; i <= B; i++ {
+		if math.Abs(a[i]-b[i]) > epsilon {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,801 @@
+package raytracer
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// Scene is a world and the camera that views it, as described by a scene
+// file. Camera is nil when the file does not add one.
+type Scene struct {
+	World  *World
+	Camera *Camera
+}
+
+// LoadSceneFile opens filename and reads it with LoadScene. OBJ files named
+// by the scene are found relative to the scene file's directory.
+func LoadSceneFile(filename string) (*Scene, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return loadScene(file, filepath.Dir(filename))
+}
+
+// LoadScene reads a scene written in the YAML format of The Ray Tracer
+// Challenge's scene files: a list of entries that each either add something
+// to the scene or define a reusable value.
+//
+//   - add: camera needs width, height, field-of-view, from, to and up.
+//   - add: light needs intensity and either at, for a point light, or corner,
+//     uvec, vvec, usteps and vsteps (and optionally jitter) for an area light.
+//   - add: sphere, plane, cube, cylinder, cone, triangle, group, csg or obj
+//     adds a shape, with an optional material and transform. Cylinders and
+//     cones take min, max and closed; triangles p1, p2 and p3; groups a list
+//     of children; csg an operation with left and right shapes; and obj the
+//     file to load. The material of a group, csg or obj applies to every
+//     shape inside it that does not set its own. Adding the name of a
+//     defined shape adds a copy of it, with any keys given here taking
+//     precedence.
+//   - define: name gives a value to a name that later materials, transforms
+//     and adds can refer to. A define whose value is a mapping can extend an
+//     earlier one, overriding its keys.
+//
+// A transform is a list of [translate, x, y, z], [scale, x, y, z],
+// [rotate-x, r], [rotate-y, r], [rotate-z, r], [shear, xy, xz, yx, yz, zx,
+// zy] and names of defined transforms, applied in order. A material sets any
+// of color, ambient, diffuse, specular, shininess, reflective, transparency,
+// refractive-index and pattern, the last being a mapping with a type
+// (stripes, gradient, rings or checkers), two colors and a transform.
+//
+// Unknown keys, missing values and malformed numbers are returned as errors
+// that name the offending line.
+func LoadScene(r io.Reader) (*Scene, error) {
+	return loadScene(r, ".")
+}
+
+func loadScene(r io.Reader, dir string) (*Scene, error) {
+	root, err := parseYAML(r)
+	if err != nil {
+		return nil, fmt.Errorf("scene %w", err)
+	}
+	l := &sceneLoader{
+		dir:     dir,
+		defines: map[string]*yamlNode{},
+		scene:   &Scene{World: NewWorld()},
+	}
+	if err := l.load(root); err != nil {
+		return nil, fmt.Errorf("scene %w", err)
+	}
+	return l.scene, nil
+}
+
+type sceneLoader struct {
+	dir     string
+	defines map[string]*yamlNode
+	scene   *Scene
+}
+
+func (l *sceneLoader) load(root *yamlNode) error {
+	if root.kind == yamlScalar && root.value == "" {
+		return nil
+	}
+	if root.kind != yamlSequence {
+		return yamlError(root.line, "expected a list of add and define entries, got %s", root.describe())
+	}
+
+	for _, entry := range root.items {
+		if entry.kind != yamlMapping {
+			return yamlError(entry.line, "expected an add or define entry, got %s", entry.describe())
+		}
+		add, define := entry.get("add"), entry.get("define")
+		var err error
+		switch {
+		case add != nil && define != nil:
+			err = yamlError(entry.line, "an entry cannot both add and define")
+		case define != nil:
+			err = l.define(entry)
+		case add != nil:
+			err = l.add(entry)
+		default:
+			err = yamlError(entry.line, "expected an add or define entry")
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *sceneLoader) define(entry *yamlNode) error {
+	if err := checkKeys(entry, "define", "extend", "value"); err != nil {
+		return err
+	}
+	name, err := sceneString(entry.get("define"))
+	if err != nil {
+		return err
+	}
+	value, err := required(entry, "value", "define")
+	if err != nil {
+		return err
+	}
+
+	if extend := entry.get("extend"); extend != nil {
+		base, err := l.lookup(extend)
+		if err != nil {
+			return err
+		}
+		if base.kind != yamlMapping || value.kind != yamlMapping {
+			return yamlError(extend.line, "only mappings can extend each other")
+		}
+		value = mergeMappings(base, value)
+	}
+	l.defines[name] = value
+	return nil
+}
+
+// lookup returns the value defined under the name in n.
+func (l *sceneLoader) lookup(n *yamlNode) (*yamlNode, error) {
+	name, err := sceneString(n)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := l.defines[name]
+	if !ok {
+		return nil, yamlError(n.line, "%q is not defined", name)
+	}
+	return value, nil
+}
+
+// mergeMappings returns a mapping with the keys of base and over, taking the
+// value from over when both have a key.
+func mergeMappings(base, over *yamlNode) *yamlNode {
+	merged := &yamlNode{kind: yamlMapping, line: over.line}
+	for i, key := range base.keys {
+		if over.get(key) == nil {
+			merged.keys = append(merged.keys, key)
+			merged.keyLines = append(merged.keyLines, base.keyLines[i])
+			merged.items = append(merged.items, base.items[i])
+		}
+	}
+	merged.keys = append(merged.keys, over.keys...)
+	merged.keyLines = append(merged.keyLines, over.keyLines...)
+	merged.items = append(merged.items, over.items...)
+	return merged
+}
+
+func (l *sceneLoader) add(entry *yamlNode) error {
+	kind, err := sceneString(entry.get("add"))
+	if err != nil {
+		return err
+	}
+	switch kind {
+	case "camera":
+		return l.camera(entry)
+	case "light":
+		return l.light(entry)
+	}
+	s, err := l.shape(entry, nil)
+	if err != nil {
+		return err
+	}
+	l.scene.World.AddObject(s)
+	return nil
+}
+
+func (l *sceneLoader) camera(entry *yamlNode) error {
+	if l.scene.Camera != nil {
+		return yamlError(entry.line, "the scene already has a camera")
+	}
+	if err := checkKeys(entry, "add", "width", "height", "field-of-view", "from", "to", "up"); err != nil {
+		return err
+	}
+
+	var width, height int
+	var fieldOfView float64
+	var from, to, up Tuple
+	err := firstError(
+		func() (err error) { width, err = requiredInt(entry, "width", "camera", 1); return },
+		func() (err error) { height, err = requiredInt(entry, "height", "camera", 1); return },
+		func() (err error) { fieldOfView, err = requiredNumber(entry, "field-of-view", "camera"); return },
+		func() (err error) { from, err = requiredTuple(entry, "from", "camera", NewPoint); return },
+		func() (err error) { to, err = requiredTuple(entry, "to", "camera", NewPoint); return },
+		func() (err error) { up, err = requiredTuple(entry, "up", "camera", NewVector); return },
+	)
+	if err != nil {
+		return err
+	}
+
+	camera := NewCamera(float64(width), float64(height), fieldOfView)
+	if err := camera.SetTransform(ViewTransform(from, to, up)); err != nil {
+		return yamlError(entry.line, "camera cannot look from %v to %v with up %v", from[:3], to[:3], up[:3])
+	}
+	l.scene.Camera = camera
+	return nil
+}
+
+func (l *sceneLoader) light(entry *yamlNode) error {
+	intensity, err := requiredTuple(entry, "intensity", "light", colorTuple)
+	if err != nil {
+		return err
+	}
+	color := NewColor(intensity[R], intensity[G], intensity[B])
+
+	if entry.get("corner") == nil {
+		if err := checkKeys(entry, "add", "at", "intensity"); err != nil {
+			return err
+		}
+		at, err := requiredTuple(entry, "at", "light", NewPoint)
+		if err != nil {
+			return err
+		}
+		l.scene.World.AddLight(&Light{Position: at, Intensity: color})
+		return nil
+	}
+
+	if err := checkKeys(entry, "add", "corner", "uvec", "usteps", "vvec", "vsteps", "jitter", "intensity"); err != nil {
+		return err
+	}
+	var corner, uvec, vvec Tuple
+	var usteps, vsteps int
+	jitter := false
+	err = firstError(
+		func() (err error) { corner, err = requiredTuple(entry, "corner", "area light", NewPoint); return },
+		func() (err error) { uvec, err = requiredTuple(entry, "uvec", "area light", NewVector); return },
+		func() (err error) { usteps, err = requiredInt(entry, "usteps", "area light", 1); return },
+		func() (err error) { vvec, err = requiredTuple(entry, "vvec", "area light", NewVector); return },
+		func() (err error) { vsteps, err = requiredInt(entry, "vsteps", "area light", 1); return },
+		func() (err error) {
+			if n := entry.get("jitter"); n != nil {
+				jitter, err = sceneBool(n)
+			}
+			return
+		},
+	)
+	if err != nil {
+		return err
+	}
+	light := NewAreaLight(corner, uvec, usteps, vvec, vsteps, color)
+	light.SetJitter(jitter)
+	l.scene.World.AddLight(light)
+	return nil
+}
+
+// shape builds the shape described by entry, a mapping whose add key names
+// its kind or a defined shape. inherited is the material of the group or csg
+// entry is nested in, if any, which entry uses unless it sets its own.
+func (l *sceneLoader) shape(entry *yamlNode, inherited *Material) (Shape, error) {
+	if entry.kind != yamlMapping {
+		return nil, yamlError(entry.line, "expected a shape, got %s", entry.describe())
+	}
+	kindNode, err := required(entry, "add", "shape")
+	if err != nil {
+		return nil, err
+	}
+	kind, err := sceneString(kindNode)
+	if err != nil {
+		return nil, err
+	}
+
+	// a defined shape is merged with the entry, which may itself add a
+	// defined shape
+	seen := map[string]bool{}
+	for {
+		defined, ok := l.defines[kind]
+		if !ok {
+			break
+		}
+		if seen[kind] || defined.kind != yamlMapping || defined.get("add") == nil {
+			return nil, yamlError(kindNode.line, "%q is not a shape", kind)
+		}
+		seen[kind] = true
+		entry = mergeMappings(defined, withoutKey(entry, "add"))
+		kindNode = entry.get("add")
+		if kind, err = sceneString(kindNode); err != nil {
+			return nil, err
+		}
+	}
+
+	material := inherited
+	if n := entry.get("material"); n != nil {
+		if material, err = l.material(n); err != nil {
+			return nil, err
+		}
+	}
+
+	common := []string{"add", "material", "transform"}
+	var s Shape
+	switch kind {
+	case "sphere":
+		s, err = NewSphere(), checkKeys(entry, common...)
+	case "plane":
+		s, err = NewPlane(), checkKeys(entry, common...)
+	case "cube":
+		s, err = NewCube(), checkKeys(entry, common...)
+	case "cylinder":
+		cy := NewCylinder()
+		err = l.truncate(entry, common, &cy.Minimum, &cy.Maximum, &cy.Closed)
+		s = cy
+	case "cone":
+		cn := NewCone()
+		err = l.truncate(entry, common, &cn.Minimum, &cn.Maximum, &cn.Closed)
+		s = cn
+	case "triangle":
+		s, err = l.triangle(entry, common)
+	case "group":
+		s, err = l.group(entry, common, material)
+	case "csg":
+		s, err = l.csg(entry, common, material)
+	case "obj":
+		s, err = l.obj(entry, common)
+	default:
+		return nil, yamlError(kindNode.line, "cannot add %q", kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// groups and csgs have already passed the material on to their children
+	if material != nil && kind != "group" && kind != "csg" {
+		setLeafMaterials(s, material)
+	}
+	if n := entry.get("transform"); n != nil {
+		t, err := l.transform(n)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.SetTransform(t); err != nil {
+			return nil, yamlError(n.line, "transform cannot be inverted")
+		}
+	}
+	return s, nil
+}
+
+// setLeafMaterials gives m to every shape in s that is not a group or csg.
+func setLeafMaterials(s Shape, m *Material) {
+	switch s := s.(type) {
+	case *Group:
+		for _, child := range s.GetChildren() {
+			setLeafMaterials(child, m)
+		}
+	case *CSG:
+		setLeafMaterials(s.GetLeft(), m)
+		setLeafMaterials(s.GetRight(), m)
+	default:
+		s.SetMaterial(m)
+	}
+}
+
+func withoutKey(n *yamlNode, key string) *yamlNode {
+	result := &yamlNode{kind: yamlMapping, line: n.line}
+	for i, k := range n.keys {
+		if k != key {
+			result.keys = append(result.keys, k)
+			result.keyLines = append(result.keyLines, n.keyLines[i])
+			result.items = append(result.items, n.items[i])
+		}
+	}
+	return result
+}
+
+// truncate reads the min, max and closed keys of a cylinder or cone.
+func (l *sceneLoader) truncate(entry *yamlNode, common []string, min, max *float64, closed *bool) error {
+	if err := checkKeys(entry, append(common, "min", "max", "closed")...); err != nil {
+		return err
+	}
+	return firstError(
+		func() (err error) {
+			if n := entry.get("min"); n != nil {
+				*min, err = sceneNumber(n)
+			}
+			return
+		},
+		func() (err error) {
+			if n := entry.get("max"); n != nil {
+				*max, err = sceneNumber(n)
+			}
+			return
+		},
+		func() (err error) {
+			if n := entry.get("closed"); n != nil {
+				*closed, err = sceneBool(n)
+			}
+			return
+		},
+	)
+}
+
+func (l *sceneLoader) triangle(entry *yamlNode, common []string) (Shape, error) {
+	if err := checkKeys(entry, append(common, "p1", "p2", "p3")...); err != nil {
+		return nil, err
+	}
+	var p [3]Tuple
+	for i, key := range []string{"p1", "p2", "p3"} {
+		var err error
+		if p[i], err = requiredTuple(entry, key, "triangle", NewPoint); err != nil {
+			return nil, err
+		}
+	}
+	return NewTriangle(p[0], p[1], p[2]), nil
+}
+
+func (l *sceneLoader) group(entry *yamlNode, common []string, material *Material) (Shape, error) {
+	if err := checkKeys(entry, append(common, "children")...); err != nil {
+		return nil, err
+	}
+	g := NewGroup()
+	children := entry.get("children")
+	if children == nil {
+		return g, nil
+	}
+	if children.kind != yamlSequence {
+		return nil, yamlError(children.line, "expected a list of shapes, got %s", children.describe())
+	}
+	for _, child := range children.items {
+		s, err := l.shape(child, material)
+		if err != nil {
+			return nil, err
+		}
+		g.AddChild(s)
+	}
+	return g, nil
+}
+
+func (l *sceneLoader) csg(entry *yamlNode, common []string, material *Material) (Shape, error) {
+	if err := checkKeys(entry, append(common, "operation", "left", "right")...); err != nil {
+		return nil, err
+	}
+	opNode, err := required(entry, "operation", "csg")
+	if err != nil {
+		return nil, err
+	}
+	op, err := sceneString(opNode)
+	if err != nil {
+		return nil, err
+	}
+	operation, ok := map[string]CSGOperation{
+		"union":        CSGUnion,
+		"intersection": CSGIntersection,
+		"difference":   CSGDifference,
+	}[op]
+	if !ok {
+		return nil, yamlError(opNode.line, "unknown csg operation %q, expected union, intersection or difference", op)
+	}
+
+	var operands [2]Shape
+	for i, key := range []string{"left", "right"} {
+		n, err := required(entry, key, "csg")
+		if err != nil {
+			return nil, err
+		}
+		if operands[i], err = l.shape(n, material); err != nil {
+			return nil, err
+		}
+	}
+	return NewCSG(operation, operands[0], operands[1]), nil
+}
+
+func (l *sceneLoader) obj(entry *yamlNode, common []string) (Shape, error) {
+	if err := checkKeys(entry, append(common, "file")...); err != nil {
+		return nil, err
+	}
+	n, err := required(entry, "file", "obj")
+	if err != nil {
+		return nil, err
+	}
+	filename, err := sceneString(n)
+	if err != nil {
+		return nil, err
+	}
+	if !filepath.IsAbs(filename) {
+		filename = filepath.Join(l.dir, filename)
+	}
+	obj, err := ParseObjFile(filename)
+	if err != nil {
+		return nil, yamlError(n.line, "%v", err)
+	}
+	return obj.ToGroup(), nil
+}
+
+// material builds a material from a mapping or the name of a defined one.
+func (l *sceneLoader) material(n *yamlNode) (*Material, error) {
+	if n.kind == yamlScalar {
+		defined, err := l.lookup(n)
+		if err != nil {
+			return nil, err
+		}
+		n = defined
+	}
+	if n.kind != yamlMapping {
+		return nil, yamlError(n.line, "expected a material, got %s", n.describe())
+	}
+
+	m := DefaultMaterial()
+	setters := map[string]func(float64){
+		"ambient":          m.SetAmbient,
+		"diffuse":          m.SetDiffuse,
+		"specular":         m.SetSpecular,
+		"shininess":        m.SetShininess,
+		"reflective":       m.SetReflective,
+		"transparency":     m.SetTransparency,
+		"refractive-index": m.SetRefractiveIndex,
+	}
+	for i, key := range n.keys {
+		value := n.items[i]
+		switch key {
+		case "color":
+			c, err := sceneTuple(value, colorTuple)
+			if err != nil {
+				return nil, err
+			}
+			m.SetColor(c[R], c[G], c[B])
+		case "pattern":
+			p, err := l.pattern(value)
+			if err != nil {
+				return nil, err
+			}
+			m.Pattern = p
+		default:
+			set, ok := setters[key]
+			if !ok {
+				return nil, yamlError(n.keyLines[i], "unknown material key %q", key)
+			}
+			f, err := sceneNumber(value)
+			if err != nil {
+				return nil, err
+			}
+			set(f)
+		}
+	}
+	return m, nil
+}
+
+func (l *sceneLoader) pattern(n *yamlNode) (Pattern, error) {
+	if n.kind != yamlMapping {
+		return nil, yamlError(n.line, "expected a pattern, got %s", n.describe())
+	}
+	if err := checkKeys(n, "type", "colors", "transform"); err != nil {
+		return nil, err
+	}
+	typeNode, err := required(n, "type", "pattern")
+	if err != nil {
+		return nil, err
+	}
+	kind, err := sceneString(typeNode)
+	if err != nil {
+		return nil, err
+	}
+	newPattern, ok := map[string]func(a, b Color) Pattern{
+		"stripes":  func(a, b Color) Pattern { return NewStripePattern(a, b) },
+		"gradient": func(a, b Color) Pattern { return NewGradientPattern(a, b) },
+		"rings":    func(a, b Color) Pattern { return NewRingPattern(a, b) },
+		"checkers": func(a, b Color) Pattern { return NewChecker3DPattern(a, b) },
+	}[kind]
+	if !ok {
+		return nil, yamlError(typeNode.line, "unknown pattern type %q, expected stripes, gradient, rings or checkers", kind)
+	}
+
+	colors, err := required(n, "colors", "pattern")
+	if err != nil {
+		return nil, err
+	}
+	if colors.kind != yamlSequence || len(colors.items) != 2 {
+		return nil, yamlError(colors.line, "a pattern needs a list of 2 colors, got %s", colors.describe())
+	}
+	var c [2]Color
+	for i, item := range colors.items {
+		t, err := sceneTuple(item, colorTuple)
+		if err != nil {
+			return nil, err
+		}
+		c[i] = NewColor(t[R], t[G], t[B])
+	}
+
+	p := newPattern(c[0], c[1])
+	if t := n.get("transform"); t != nil {
+		m, err := l.transform(t)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.SetTransform(m); err != nil {
+			return nil, yamlError(t.line, "transform cannot be inverted")
+		}
+	}
+	return p, nil
+}
+
+// transform combines a list of transformations, and names of defined lists,
+// into one matrix that applies them in order.
+func (l *sceneLoader) transform(n *yamlNode) (Matrix, error) {
+	return l.transformDepth(n, 0)
+}
+
+func (l *sceneLoader) transformDepth(n *yamlNode, depth int) (Matrix, error) {
+	if depth > len(l.defines) {
+		return Matrix{}, yamlError(n.line, "transform refers to itself")
+	}
+	if n.kind == yamlScalar {
+		defined, err := l.lookup(n)
+		if err != nil {
+			return Matrix{}, err
+		}
+		return l.transformDepth(defined, depth+1)
+	}
+	if n.kind != yamlSequence {
+		return Matrix{}, yamlError(n.line, "expected a list of transformations, got %s", n.describe())
+	}
+
+	result := IdentityMatrix()
+	for _, item := range n.items {
+		var m Matrix
+		var err error
+		if item.kind == yamlScalar {
+			m, err = l.transformDepth(item, depth)
+		} else {
+			m, err = transformation(item)
+		}
+		if err != nil {
+			return Matrix{}, err
+		}
+		result, _ = m.MultiplyMatrices(result)
+	}
+	return result, nil
+}
+
+// transformation builds the matrix for one [name, arguments...] list.
+func transformation(n *yamlNode) (Matrix, error) {
+	if n.kind != yamlSequence || len(n.items) == 0 {
+		return Matrix{}, yamlError(n.line, "expected a transformation such as [translate, 1, 2, 3], got %s", n.describe())
+	}
+	name, err := sceneString(n.items[0])
+	if err != nil {
+		return Matrix{}, err
+	}
+	args := make([]float64, len(n.items)-1)
+	for i, item := range n.items[1:] {
+		if args[i], err = sceneNumber(item); err != nil {
+			return Matrix{}, err
+		}
+	}
+
+	counts := map[string]int{
+		"translate": 3, "scale": 3, "rotate-x": 1, "rotate-y": 1, "rotate-z": 1, "shear": 6,
+	}
+	count, ok := counts[name]
+	if !ok {
+		return Matrix{}, yamlError(n.line, "unknown transformation %q", name)
+	}
+	if len(args) != count {
+		return Matrix{}, yamlError(n.line, "%s needs %d numbers, got %d", name, count, len(args))
+	}
+
+	switch name {
+	case "translate":
+		return TranslationMatrix(args...)
+	case "scale":
+		return ScalingMatrix(args...)
+	case "rotate-x":
+		return RotationXMatrix(args[0])
+	case "rotate-y":
+		return RotationYMatrix(args[0])
+	case "rotate-z":
+		return RotationZMatrix(args[0])
+	}
+	return ShearingMatrix(args[0], args[1], args[2], args[3], args[4], args[5])
+}
+
+// checkKeys returns an error naming the first key of n that is not allowed.
+func checkKeys(n *yamlNode, allowed ...string) error {
+	for i, key := range n.keys {
+		ok := false
+		for _, a := range allowed {
+			if key == a {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return yamlError(n.keyLines[i], "unknown key %q", key)
+		}
+	}
+	return nil
+}
+
+func firstError(steps ...func() error) error {
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func required(n *yamlNode, key, what string) (*yamlNode, error) {
+	value := n.get(key)
+	if value == nil {
+		return nil, yamlError(n.line, "%s needs %s", what, key)
+	}
+	return value, nil
+}
+
+func requiredNumber(n *yamlNode, key, what string) (float64, error) {
+	value, err := required(n, key, what)
+	if err != nil {
+		return 0, err
+	}
+	return sceneNumber(value)
+}
+
+// requiredInt reads key as an integer no smaller than min.
+func requiredInt(n *yamlNode, key, what string, min int) (int, error) {
+	value, err := required(n, key, what)
+	if err != nil {
+		return 0, err
+	}
+	i, err := strconv.Atoi(value.value)
+	if value.kind != yamlScalar || err != nil || i < min {
+		return 0, yamlError(value.line, "%s must be a whole number of at least %d, got %s", key, min, value.describe())
+	}
+	return i, nil
+}
+
+func requiredTuple(n *yamlNode, key, what string, build func(x, y, z float64) Tuple) (Tuple, error) {
+	value, err := required(n, key, what)
+	if err != nil {
+		return nil, err
+	}
+	return sceneTuple(value, build)
+}
+
+func colorTuple(r, g, b float64) Tuple {
+	return NewTuple(r, g, b)
+}
+
+func sceneString(n *yamlNode) (string, error) {
+	if n.kind != yamlScalar || n.value == "" {
+		return "", yamlError(n.line, "expected a name, got %s", n.describe())
+	}
+	return n.value, nil
+}
+
+func sceneNumber(n *yamlNode) (float64, error) {
+	if n.kind == yamlScalar {
+		f, err := strconv.ParseFloat(n.value, 64)
+		if err == nil && !math.IsNaN(f) {
+			return f, nil
+		}
+	}
+	return 0, yamlError(n.line, "expected a number, got %s", n.describe())
+}
+
+func sceneBool(n *yamlNode) (bool, error) {
+	if n.kind == yamlScalar {
+		switch n.value {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+	}
+	return false, yamlError(n.line, "expected true or false, got %s", n.describe())
+}
+
+// sceneTuple reads a list of three numbers and passes them to build.
+func sceneTuple(n *yamlNode, build func(x, y, z float64) Tuple) (Tuple, error) {
+	if n.kind != yamlSequence || len(n.items) != 3 {
+		return nil, yamlError(n.line, "expected a list of 3 numbers, got %s", n.describe())
+	}
+	var v [3]float64
+	for i, item := range n.items {
+		var err error
+		if v[i], err = sceneNumber(item); err != nil {
+			return nil, err
+		}
+	}
+	return build(v[0], v[1], v[2]), nil
+}
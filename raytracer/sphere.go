@@ -3,52 +3,76 @@ package raytracer
 import "math"
 
 type Sphere struct {
-	transform Matrix
-	material  *Material
+	transform        Matrix
+	inverse          Mat4
+	inverseTranspose Mat4
+	material         *Material
+	parent           Shape
 }
 
 func NewSphere() *Sphere {
 	return &Sphere{
-		transform: IdentityMatrix(),
-		material:  DefaultMaterial(),
+		transform:        IdentityMatrix(),
+		inverse:          Identity4(),
+		inverseTranspose: Identity4(),
+		material:         DefaultMaterial(),
 	}
 }
 
-func (s *Sphere) SetTransform(m Matrix) {
-	s.transform = m
+// SetTransform sets the shape's transform and caches its inverse and
+// inverse-transpose, so rays and normals are not re-inverted on every hit. It
+// returns an error, leaving the shape unchanged, when m cannot be inverted.
+func (s *Sphere) SetTransform(m Matrix) error {
+	inv, invT, err := invertTransform(m)
+	if err != nil {
+		return err
+	}
+	s.transform, s.inverse, s.inverseTranspose = m, inv, invT
+	return nil
 }
 
 func (s *Sphere) GetTransformMatrix() Matrix {
 	return s.transform
 }
 
+func (s *Sphere) GetInverseMatrix() Mat4 {
+	return s.inverse
+}
+
+func (s *Sphere) GetInverseTransposeMatrix() Mat4 {
+	return s.inverseTranspose
+}
+
 func (s *Sphere) GetMaterial() *Material {
 	return s.material
 }
 
-func (s *Sphere) NormalAt(worldPoint Tuple) Tuple {
+func (s *Sphere) GetParent() Shape {
+	return s.parent
+}
+
+func (s *Sphere) setParent(parent Shape) {
+	s.parent = parent
+}
 
-	tm, _ := s.GetTransformMatrix().Inverse()
-	objectSpacePoint, _ := tm.MultiplyWithTuple(worldPoint)
+func (s *Sphere) NormalAt(worldPoint Tuple) Tuple {
+	return normalAt(s, worldPoint.Vec4(), Intersection{}).Tuple()
+}
 
-	objectSpaceNormal, _ := objectSpacePoint.Subtract(NewPoint(0, 0, 0))
-	tmt, _ := tm.Transpose()
+func (s *Sphere) localNormalAt(objectPoint Vec4, hit Intersection) Vec4 {
+	return objectPoint.Sub(Point4(0, 0, 0))
+}
 
-	worldNormal, _ := tmt.MultiplyWithTuple(objectSpaceNormal)
-	worldNormal[W] = 0
-	worldNormal, _ = worldNormal.Normalize()
-	return worldNormal
+func (s *Sphere) Bounds() BoundingBox {
+	return NewBoundingBox(NewPoint(-1, -1, -1), NewPoint(1, 1, 1))
 }
 
 func (s *Sphere) Intersect(r Ray) []Intersection {
-	m, _ := s.GetTransformMatrix().Inverse()
-	r2 := r.Transform(m)
-	sphereToRay, _ := r2.origin.Subtract(NewPoint(0, 0, 0))
-	a, _ := Dot(r2.Direction(), r2.Direction())
-	b, _ := Dot(r2.Direction(), sphereToRay)
-	b *= 2
-	c, _ := Dot(sphereToRay, sphereToRay)
-	c -= 1
+	r2 := r.transform(s.inverse)
+	sphereToRay := r2.origin.Sub(Point4(0, 0, 0))
+	a := r2.direction.Dot(r2.direction)
+	b := 2 * r2.direction.Dot(sphereToRay)
+	c := sphereToRay.Dot(sphereToRay) - 1
 	discriminant := b*b - 4*a*c
 	if discriminant < 0 {
 		return nil
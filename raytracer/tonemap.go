@@ -0,0 +1,133 @@
+package raytracer
+
+import "math"
+
+// ToneMapper compresses a linear colour, whose channels may be far above 1,
+// into the 0-1 range that 8-bit formats can store.
+type ToneMapper interface {
+	Map(c Color) Color
+}
+
+// ClampToneMapper cuts every channel off at 1, as CanvasToPPM always has.
+type ClampToneMapper struct{}
+
+func NewClampToneMapper() ClampToneMapper {
+	return ClampToneMapper{}
+}
+
+func (m ClampToneMapper) Map(c Color) Color {
+	return mapChannels(c, func(v float64) float64 {
+		return clamp(v, 0, 1)
+	})
+}
+
+// ReinhardToneMapper maps each channel v to v(1 + v/white²)/(1 + v), which
+// leaves dark values almost untouched and brings white, and anything
+// brighter, to 1. An infinite white point gives the basic v/(1 + v) curve,
+// which never quite reaches 1.
+type ReinhardToneMapper struct {
+	white float64
+}
+
+// NewReinhardToneMapper returns a Reinhard tone mapper with the given white
+// point. A white point of zero or less means there is none, as if it were
+// infinite.
+func NewReinhardToneMapper(white float64) ReinhardToneMapper {
+	return ReinhardToneMapper{white: white}
+}
+
+func (m ReinhardToneMapper) Map(c Color) Color {
+	inverseWhiteSquared := 0.0
+	if m.white > 0 {
+		inverseWhiteSquared = 1 / (m.white * m.white)
+	}
+	return mapChannels(c, func(v float64) float64 {
+		v = math.Max(v, 0)
+		return math.Min(v*(1+v*inverseWhiteSquared)/(1+v), 1)
+	})
+}
+
+// ACESToneMapper applies Narkowicz's fit of the ACES filmic curve, which adds
+// a slight toe in the shadows and rolls highlights off smoothly to 1.
+type ACESToneMapper struct{}
+
+func NewACESToneMapper() ACESToneMapper {
+	return ACESToneMapper{}
+}
+
+func (m ACESToneMapper) Map(c Color) Color {
+	const a, b, cc, d, e = 2.51, 0.03, 2.43, 0.59, 0.14
+	return mapChannels(c, func(v float64) float64 {
+		v = math.Max(v, 0)
+		return clamp(v*(a*v+b)/(v*(cc*v+d)+e), 0, 1)
+	})
+}
+
+func mapChannels(c Color, f func(float64) float64) Color {
+	return NewColor(f(c[R]), f(c[G]), f(c[B]))
+}
+
+// ToneMap returns a copy of the canvas scaled by 2^exposure, so each stop of
+// exposure doubles the brightness, and then passed through mapper.
+func (c *Canvas) ToneMap(exposure float64, mapper ToneMapper) Canvas {
+	scale := math.Exp2(exposure)
+	return c.mapPixels(func(p Color) Color {
+		return mapper.Map(p.MultiplyByScalar(scale))
+	})
+}
+
+// EncodeSRGB returns a copy of the canvas with the sRGB transfer function
+// applied, for formats such as PPM that store values exactly as given. PNG
+// output and At already encode, so must be given the linear canvas.
+func (c *Canvas) EncodeSRGB() Canvas {
+	return c.mapPixels(LinearToSRGB)
+}
+
+// DecodeSRGB is the inverse of EncodeSRGB.
+func (c *Canvas) DecodeSRGB() Canvas {
+	return c.mapPixels(SRGBToLinear)
+}
+
+// LinearToSRGB clamps each channel of a linear colour to 0-1 and applies the
+// sRGB transfer function.
+func LinearToSRGB(c Color) Color {
+	return mapChannels(c, encodeSRGB)
+}
+
+// SRGBToLinear is the inverse of LinearToSRGB.
+func SRGBToLinear(c Color) Color {
+	return mapChannels(c, decodeSRGB)
+}
+
+// encodeSRGB clamps a linear channel value to 0-1 and applies the sRGB
+// transfer function.
+func encodeSRGB(v float64) float64 {
+	v = clamp(v, 0, 1)
+	switch {
+	case v <= 0.0031308:
+		return 12.92 * v
+	case v == 1:
+		// the curve below rounds to just under 1, which PPM's truncation to
+		// 8 bits would turn into 254
+		return 1
+	}
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
+
+// decodeSRGB is the inverse of encodeSRGB.
+func decodeSRGB(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func (c *Canvas) mapPixels(f func(Color) Color) Canvas {
+	result := NewCanvas(c.width, c.Height)
+	for y := 0; y < c.Height; y++ {
+		for x := 0; x < c.width; x++ {
+			result.pixels[y][x] = f(c.pixels[y][x])
+		}
+	}
+	return result
+}
@@ -0,0 +1,244 @@
+package raytracer
+
+import "math"
+
+// Triangle is a flat triangle defined by three points in object space. The
+// edges and face normal are computed once when it is created.
+type Triangle struct {
+	transform        Matrix
+	inverse          Mat4
+	inverseTranspose Mat4
+	material         *Material
+	parent           Shape
+	p1               Vec4
+	p2               Vec4
+	p3               Vec4
+	e1               Vec4
+	e2               Vec4
+	normal           Vec4
+}
+
+func NewTriangle(p1, p2, p3 Tuple) *Triangle {
+	v1, v2, v3 := p1.Vec4(), p2.Vec4(), p3.Vec4()
+	e1 := v2.Sub(v1)
+	e2 := v3.Sub(v1)
+
+	return &Triangle{
+		transform:        IdentityMatrix(),
+		inverse:          Identity4(),
+		inverseTranspose: Identity4(),
+		material:         DefaultMaterial(),
+		p1:               v1,
+		p2:               v2,
+		p3:               v3,
+		e1:               e1,
+		e2:               e2,
+		normal:           e2.Cross(e1).Normalize(),
+	}
+}
+
+func (tr *Triangle) SetTransform(m Matrix) error {
+	inv, invT, err := invertTransform(m)
+	if err != nil {
+		return err
+	}
+	tr.transform, tr.inverse, tr.inverseTranspose = m, inv, invT
+	return nil
+}
+
+func (tr *Triangle) GetTransformMatrix() Matrix {
+	return tr.transform
+}
+
+func (tr *Triangle) GetInverseMatrix() Mat4 {
+	return tr.inverse
+}
+
+func (tr *Triangle) GetInverseTransposeMatrix() Mat4 {
+	return tr.inverseTranspose
+}
+
+func (tr *Triangle) GetMaterial() *Material {
+	return tr.material
+}
+
+func (tr *Triangle) SetMaterial(material *Material) {
+	tr.material = material
+}
+
+func (tr *Triangle) GetParent() Shape {
+	return tr.parent
+}
+
+func (tr *Triangle) setParent(parent Shape) {
+	tr.parent = parent
+}
+
+// GetPoints returns the triangle's three vertices in object space.
+func (tr *Triangle) GetPoints() (Tuple, Tuple, Tuple) {
+	return tr.p1.Tuple(), tr.p2.Tuple(), tr.p3.Tuple()
+}
+
+func (tr *Triangle) NormalAt(worldPoint Tuple) Tuple {
+	return normalAt(tr, worldPoint.Vec4(), Intersection{}).Tuple()
+}
+
+func (tr *Triangle) localNormalAt(objectPoint Vec4, hit Intersection) Vec4 {
+	return tr.normal
+}
+
+func (tr *Triangle) Bounds() BoundingBox {
+	return EmptyBoundingBox().AddPoint(tr.p1.Tuple()).AddPoint(tr.p2.Tuple()).AddPoint(tr.p3.Tuple())
+}
+
+func (tr *Triangle) Intersect(r Ray) []Intersection {
+	//Convert ray into object space
+	localRay := r.transform(tr.inverse)
+
+	t, u, v, ok := intersectTriangle(localRay, tr.p1, tr.e1, tr.e2)
+	if !ok {
+		return nil
+	}
+	return []Intersection{{t: t, o: tr, u: u, v: v}}
+}
+
+// intersectTriangle implements the Möller–Trumbore algorithm. Besides t it
+// returns the barycentric coordinates u and v of the hit, measured along e1
+// and e2 from p1.
+func intersectTriangle(r Ray, p1, e1, e2 Vec4) (float64, float64, float64, bool) {
+	dirCrossE2 := r.direction.Cross(e2)
+	det := e1.Dot(dirCrossE2)
+	// the ray is parallel to the triangle's plane
+	if math.Abs(det) < EPSILON {
+		return 0, 0, 0, false
+	}
+
+	f := 1.0 / det
+	p1ToOrigin := r.origin.Sub(p1)
+	u := f * p1ToOrigin.Dot(dirCrossE2)
+	if u < 0 || u > 1 {
+		return 0, 0, 0, false
+	}
+
+	originCrossE1 := p1ToOrigin.Cross(e1)
+	v := f * r.direction.Dot(originCrossE1)
+	if v < 0 || u+v > 1 {
+		return 0, 0, 0, false
+	}
+
+	return f * e2.Dot(originCrossE1), u, v, true
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// SmoothTriangle is a triangle with a normal at each vertex. The normal at a
+// hit is interpolated from them using the hit's u and v, which makes a mesh of
+// smooth triangles look curved.
+type SmoothTriangle struct {
+	transform        Matrix
+	inverse          Mat4
+	inverseTranspose Mat4
+	material         *Material
+	parent           Shape
+	p1               Vec4
+	p2               Vec4
+	p3               Vec4
+	n1               Vec4
+	n2               Vec4
+	n3               Vec4
+	e1               Vec4
+	e2               Vec4
+}
+
+func NewSmoothTriangle(p1, p2, p3, n1, n2, n3 Tuple) *SmoothTriangle {
+	v1, v2, v3 := p1.Vec4(), p2.Vec4(), p3.Vec4()
+
+	return &SmoothTriangle{
+		transform:        IdentityMatrix(),
+		inverse:          Identity4(),
+		inverseTranspose: Identity4(),
+		material:         DefaultMaterial(),
+		p1:               v1,
+		p2:               v2,
+		p3:               v3,
+		n1:               n1.Vec4(),
+		n2:               n2.Vec4(),
+		n3:               n3.Vec4(),
+		e1:               v2.Sub(v1),
+		e2:               v3.Sub(v1),
+	}
+}
+
+func (st *SmoothTriangle) SetTransform(m Matrix) error {
+	inv, invT, err := invertTransform(m)
+	if err != nil {
+		return err
+	}
+	st.transform, st.inverse, st.inverseTranspose = m, inv, invT
+	return nil
+}
+
+func (st *SmoothTriangle) GetTransformMatrix() Matrix {
+	return st.transform
+}
+
+func (st *SmoothTriangle) GetInverseMatrix() Mat4 {
+	return st.inverse
+}
+
+func (st *SmoothTriangle) GetInverseTransposeMatrix() Mat4 {
+	return st.inverseTranspose
+}
+
+func (st *SmoothTriangle) GetMaterial() *Material {
+	return st.material
+}
+
+func (st *SmoothTriangle) SetMaterial(material *Material) {
+	st.material = material
+}
+
+func (st *SmoothTriangle) GetParent() Shape {
+	return st.parent
+}
+
+func (st *SmoothTriangle) setParent(parent Shape) {
+	st.parent = parent
+}
+
+// GetPoints returns the triangle's three vertices in object space.
+func (st *SmoothTriangle) GetPoints() (Tuple, Tuple, Tuple) {
+	return st.p1.Tuple(), st.p2.Tuple(), st.p3.Tuple()
+}
+
+// GetNormals returns the normals at each of the triangle's vertices.
+func (st *SmoothTriangle) GetNormals() (Tuple, Tuple, Tuple) {
+	return st.n1.Tuple(), st.n2.Tuple(), st.n3.Tuple()
+}
+
+// NormalAt has no hit to interpolate with, so it returns the normal at p1.
+// Shading goes through PrepareComputations, which passes the real hit.
+func (st *SmoothTriangle) NormalAt(worldPoint Tuple) Tuple {
+	return normalAt(st, worldPoint.Vec4(), Intersection{}).Tuple()
+}
+
+func (st *SmoothTriangle) localNormalAt(objectPoint Vec4, hit Intersection) Vec4 {
+	return st.n2.Scale(hit.u).
+		Add(st.n3.Scale(hit.v)).
+		Add(st.n1.Scale(1 - hit.u - hit.v))
+}
+
+func (st *SmoothTriangle) Bounds() BoundingBox {
+	return EmptyBoundingBox().AddPoint(st.p1.Tuple()).AddPoint(st.p2.Tuple()).AddPoint(st.p3.Tuple())
+}
+
+func (st *SmoothTriangle) Intersect(r Ray) []Intersection {
+	//Convert ray into object space
+	localRay := r.transform(st.inverse)
+
+	t, u, v, ok := intersectTriangle(localRay, st.p1, st.e1, st.e2)
+	if !ok {
+		return nil
+	}
+	return []Intersection{{t: t, o: st, u: u, v: v}}
+}
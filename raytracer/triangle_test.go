@@ -0,0 +1,47 @@
+package raytracer
+
+import "testing"
+
+func TestTriangleConstruction(t *testing.T) {
+	p1, p2, p3 := NewPoint(0, 1, 0), NewPoint(-1, 0, 0), NewPoint(1, 0, 0)
+	tr := NewTriangle(p1, p2, p3)
+
+	if !tr.e1.Equals(Vector4(-1, -1, 0)) {
+		t.Errorf("Expected e1 = (-1, -1, 0), got %v", tr.e1)
+	}
+	if !tr.e2.Equals(Vector4(1, -1, 0)) {
+		t.Errorf("Expected e2 = (1, -1, 0), got %v", tr.e2)
+	}
+	if !tr.normal.Equals(Vector4(0, 0, -1)) {
+		t.Errorf("Expected normal = (0, 0, -1), got %v", tr.normal)
+	}
+}
+
+func TestSmoothTriangleNormal(t *testing.T) {
+	tri := NewSmoothTriangle(
+		NewPoint(0, 1, 0), NewPoint(-1, 0, 0), NewPoint(1, 0, 0),
+		NewVector(0, 1, 0), NewVector(-1, 0, 0), NewVector(1, 0, 0),
+	)
+
+	t.Run("A smooth triangle uses u/v to interpolate the normal", func(t *testing.T) {
+		i := Intersection{t: 1, o: tri, u: 0.45, v: 0.25}
+		n := normalAt(tri, Point4(0, 0, 0), i)
+
+		expected := Vector4(-0.5547, 0.83205, 0)
+		if !n.Equals(expected) {
+			t.Errorf("Expected %v, got %v", expected, n)
+		}
+	})
+
+	t.Run("Preparing the normal on a smooth triangle", func(t *testing.T) {
+		i := Intersection{t: 1, o: tri, u: 0.45, v: 0.25}
+		r := NewRay(NewPoint(-0.2, 0.3, -2), NewVector(0, 0, 1))
+
+		comps := PrepareComputations(i, r, i)
+
+		expected := NewVector(-0.5547, 0.83205, 0)
+		if !comps.normalv.Equals(expected.Vec4()) {
+			t.Errorf("Expected %v, got %v", expected, comps.normalv)
+		}
+	})
+}
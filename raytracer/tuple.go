@@ -172,33 +172,35 @@ func equalsWithMargin(a, b float64) bool {
 	return false
 }
 
-type Color struct {
-	Tuple
-}
+// Color is a linear RGB value. Like Vec4 it is a fixed-size value type, so
+// shading can pass colors around without allocating.
+type Color [3]float64
 
 func NewColor(r, g, b float64) Color {
-	return Color{NewTuple(r, g, b)}
+	return Color{r, g, b}
 }
 
 func (c Color) AddColor(other Color) Color {
-	t, _ := c.Add(other.Tuple)
-	return Color{t}
+	return Color{c[R] + other[R], c[G] + other[G], c[B] + other[B]}
 }
 
 func (c Color) SubtractColor(other Color) Color {
-	t, _ := c.Subtract(other.Tuple)
-	return Color{t}
+	return Color{c[R] - other[R], c[G] - other[G], c[B] - other[B]}
 }
 
 func (c Color) MultiplyByScalar(scalar float64) Color {
-	return Color{c.Multiply(scalar)}
+	return Color{c[R] * scalar, c[G] * scalar, c[B] * scalar}
 }
 
 func (c Color) MultiplyOtherColor(other Color) Color {
-	r, g, b := c.Tuple[R]*other.Tuple[R], c.Tuple[G]*other.Tuple[G], c.Tuple[B]*other.Tuple[B]
-	return Color{NewTuple(r, g, b)}
+	return Color{c[R] * other[R], c[G] * other[G], c[B] * other[B]}
 }
 
 func (c Color) Equals(other Color) bool {
-	return c.Tuple.Equals(other.Tuple)
+	for i := range c {
+		if !equalsWithMargin(c[i], other[i]) {
+			return false
+		}
+	}
+	return true
 }
@@ -0,0 +1,80 @@
+package raytracer
+
+import "math"
+
+// Vec4 is a fixed-size, value-type point or vector. Unlike Tuple it lives on
+// the stack and its operations cannot fail, which makes it the type used on
+// the hot path of rendering: rays, intersections, normals and shading.
+type Vec4 [4]float64
+
+func Point4(x, y, z float64) Vec4 {
+	return Vec4{x, y, z, 1.0}
+}
+
+func Vector4(x, y, z float64) Vec4 {
+	return Vec4{x, y, z, 0.0}
+}
+
+// Vec4 converts t to a Vec4. Missing components are zero and any beyond the
+// fourth are dropped.
+func (t Tuple) Vec4() Vec4 {
+	var v Vec4
+	copy(v[:], t)
+	return v
+}
+
+func (v Vec4) Tuple() Tuple {
+	return NewTuple(v[X], v[Y], v[Z], v[W])
+}
+
+func (v Vec4) Equals(other Vec4) bool {
+	for i := range v {
+		if !equalsWithMargin(v[i], other[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (v Vec4) Add(other Vec4) Vec4 {
+	return Vec4{v[X] + other[X], v[Y] + other[Y], v[Z] + other[Z], v[W] + other[W]}
+}
+
+func (v Vec4) Sub(other Vec4) Vec4 {
+	return Vec4{v[X] - other[X], v[Y] - other[Y], v[Z] - other[Z], v[W] - other[W]}
+}
+
+func (v Vec4) Scale(scalar float64) Vec4 {
+	return Vec4{v[X] * scalar, v[Y] * scalar, v[Z] * scalar, v[W] * scalar}
+}
+
+func (v Vec4) Negate() Vec4 {
+	return Vec4{-v[X], -v[Y], -v[Z], -v[W]}
+}
+
+// Dot ignores w, so it can be used on points that have been offset from the
+// origin as well as on vectors.
+func (v Vec4) Dot(other Vec4) float64 {
+	return v[X]*other[X] + v[Y]*other[Y] + v[Z]*other[Z]
+}
+
+func (v Vec4) Cross(other Vec4) Vec4 {
+	return Vector4(
+		v[Y]*other[Z]-v[Z]*other[Y],
+		v[Z]*other[X]-v[X]*other[Z],
+		v[X]*other[Y]-v[Y]*other[X],
+	)
+}
+
+func (v Vec4) Magnitude() float64 {
+	return math.Sqrt(v.Dot(v))
+}
+
+func (v Vec4) Normalize() Vec4 {
+	return v.Scale(1 / v.Magnitude())
+}
+
+// Reflect reflects v around normal.
+func (v Vec4) Reflect(normal Vec4) Vec4 {
+	return v.Sub(normal.Scale(2 * v.Dot(normal)))
+}
@@ -1,21 +1,38 @@
 package raytracer
 
-import "sort"
-
+import (
+	"math"
+	"math/rand/v2"
+)
+
+// World holds the objects and lights of a scene. Objects are searched through
+// a bounding volume hierarchy that is built on the first intersection, even
+// when several goroutines intersect at once, and discarded by AddObject. Call
+// BuildBVH after moving objects that are already in the world.
 type World struct {
 	objects []Shape
-	light   *Light
+	lights  []LightSource
+	bvh     *lazyBVH
 }
 
 func NewWorld() *World {
 	return &World{
 		objects: []Shape{},
-		light:   nil,
+		lights:  []LightSource{},
+		bvh:     &lazyBVH{},
+	}
+}
+
+// GetLight returns the world's first light, or nil when it has none.
+func (w *World) GetLight() LightSource {
+	if len(w.lights) == 0 {
+		return nil
 	}
+	return w.lights[0]
 }
 
-func (w *World) GetLight() *Light {
-	return w.light
+func (w *World) GetLights() []LightSource {
+	return w.lights
 }
 
 func (w *World) GetObjects() []Shape {
@@ -23,10 +40,10 @@ func (w *World) GetObjects() []Shape {
 }
 
 func (w *World) DefaultWorld() {
-	w.light = &Light{
+	w.lights = []LightSource{&Light{
 		Position:  NewPoint(-10, 10, -10),
 		Intensity: NewColor(1, 1, 1),
-	}
+	}}
 	s1 := NewSphere()
 	s1.material.color = NewColor(0.8, 0.1, 0.6)
 	s1.material.diffuse = 0.7
@@ -35,36 +52,47 @@ func (w *World) DefaultWorld() {
 	m, _ := ScalingMatrix(0.5, 0.5, 0.5)
 	s2.SetTransform(m)
 	w.objects = append(w.objects, s1, s2)
+	w.bvh = &lazyBVH{}
 }
 
+// ColorAt returns the colour seen along r, following at most remaining
+// reflections and refractions. Jittered area lights draw from a fixed seed;
+// Camera.Render gives every pixel its own.
 func (w *World) ColorAt(r Ray, remaining int) Color {
+	return w.colorAt(r, remaining, fixedRNG())
+}
+
+func (w *World) colorAt(r Ray, remaining int, rng *rand.Rand) Color {
 	xs := w.IntersectWorld(r)
 	hit := Hit(xs)
 	if hit == nil {
 		return NewColor(0.0, 0.0, 0.0)
 	}
-	c := PrepareComputations(*hit, r)
-	return w.ShadeHits(c, remaining)
+	c := PrepareComputations(*hit, r, xs...)
+	return w.shadeHits(c, remaining, rng)
 }
 
 func (w *World) IntersectWorld(r Ray) []Intersection {
-	var xs []Intersection
-	for _, object := range w.objects {
-		//We are in object space here to calculate the intersections!
-		xs = append(xs, object.Intersect(r)...)
-	}
-	sort.Slice(xs, func(i, j int) bool {
-		return xs[i].t < xs[j].t
-	})
+	//We are in object space here to calculate the intersections!
+	xs := w.bvh.get(w.objects).intersect(r, nil)
+	sortIntersections(xs)
 	return xs
 }
 
-func (w *World) IsShadowed(p Tuple) bool {
-	v, _ := w.light.Position.Subtract(p)
-	distance, _ := v.Magnitude()
-	direction, _ := v.Normalize()
+// IsShadowed reports whether anything lies between p and lightPosition.
+func (w *World) IsShadowed(lightPosition, p Tuple) bool {
+	return w.isShadowed(lightPosition.Vec4(), p.Vec4())
+}
+
+func (w *World) isShadowed(lightPosition, p Vec4) bool {
+	sample := sampleTowards(lightPosition, p, false)
+	return w.isOccluded(p, sample.direction, sample.distance)
+}
 
-	r := NewRay(p, direction)
+// isOccluded reports whether anything lies within distance of p along
+// direction.
+func (w *World) isOccluded(p, direction Vec4, distance float64) bool {
+	r := Ray{origin: p, direction: direction}
 
 	xs := w.IntersectWorld(r)
 	h := Hit(xs)
@@ -74,22 +102,85 @@ func (w *World) IsShadowed(p Tuple) bool {
 	return false
 }
 
+// IntensityAt returns the fraction of light's samples that are visible from
+// p: 0 or 1 for a point, spot or directional light, and anything in between
+// for an area light.
+func (w *World) IntensityAt(light LightSource, p Tuple) float64 {
+	return w.intensityAt(light, p.Vec4(), fixedRNG())
+}
+
+func (w *World) intensityAt(light LightSource, p Vec4, rng *rand.Rand) float64 {
+	samples := light.sampleCount()
+	visible := 0
+	for i := 0; i < samples; i++ {
+		sample := light.sampleFrom(i, p, rng)
+		if !w.isOccluded(p, sample.direction, sample.distance) {
+			visible++
+		}
+	}
+	return float64(visible) / float64(samples)
+}
+
+// ShadeHits sums the contribution of every light, each with its own shadow
+// test, and adds the reflected and refracted colour on top.
 func (w *World) ShadeHits(comps Computation, remaining int) Color {
-	surface := Lighting(*comps.o.GetMaterial(), comps.o, *w.light, comps.overpoint, comps.eyev, comps.normalv,
-		w.IsShadowed(comps.overpoint))
-	reflected := w.ReflectedColor(comps, remaining)
-	return surface.AddColor(reflected)
+	return w.shadeHits(comps, remaining, fixedRNG())
 }
 
-func (w *World) SetLight(l *Light) {
-	w.light = l
+func (w *World) shadeHits(comps Computation, remaining int, rng *rand.Rand) Color {
+	surface := NewColor(0, 0, 0)
+	for _, light := range w.lights {
+		surface = surface.AddColor(lighting(*comps.o.GetMaterial(), comps.o, light, comps.overpoint, comps.eyev,
+			comps.normalv, w.intensityAt(light, comps.overpoint, rng), rng))
+	}
+	reflected := w.reflectedColor(comps, remaining, rng)
+	refracted := w.refractedColor(comps, remaining, rng)
+
+	material := comps.o.GetMaterial()
+	if material.reflective > 0 && material.transparency > 0 {
+		reflectance := Schlick(comps)
+		return surface.AddColor(reflected.MultiplyByScalar(reflectance)).
+			AddColor(refracted.MultiplyByScalar(1 - reflectance))
+	}
+	return surface.AddColor(reflected).AddColor(refracted)
+}
+
+// SetLight replaces all of the world's lights with l.
+func (w *World) SetLight(l LightSource) {
+	w.lights = []LightSource{l}
+}
+
+func (w *World) AddLight(l LightSource) {
+	w.lights = append(w.lights, l)
 }
 
 func (w *World) AddObject(o Shape) {
 	w.objects = append(w.objects, o)
+	w.bvh = &lazyBVH{}
+}
+
+// Bounds returns a box around every object in the world, which is infinite
+// when the world holds a plane.
+func (w *World) Bounds() BoundingBox {
+	b := EmptyBoundingBox()
+	for _, o := range w.objects {
+		b = b.Merge(ParentSpaceBounds(o))
+	}
+	return b
+}
+
+// BuildBVH rebuilds the bounding volume hierarchy over the world's objects,
+// and builds the hierarchies of any groups that have not been intersected
+// yet. A group keeps its hierarchy until a child is added to it.
+func (w *World) BuildBVH() {
+	w.bvh = builtBVH(w.objects)
 }
 
 func (w *World) ReflectedColor(comps Computation, remaining int) Color {
+	return w.reflectedColor(comps, remaining, fixedRNG())
+}
+
+func (w *World) reflectedColor(comps Computation, remaining int, rng *rand.Rand) Color {
 
 	if remaining <= 0 {
 		return NewColor(0, 0, 0)
@@ -98,7 +189,37 @@ func (w *World) ReflectedColor(comps Computation, remaining int) Color {
 	if comps.o.GetMaterial().reflective == 0 {
 		return NewColor(0, 0, 0)
 	}
-	reflectRay := NewRay(comps.overpoint, comps.reflectv)
-	color := w.ColorAt(reflectRay, remaining-1)
+	reflectRay := Ray{origin: comps.overpoint, direction: comps.reflectv}
+	color := w.colorAt(reflectRay, remaining-1, rng)
 	return color.MultiplyByScalar(comps.o.GetMaterial().reflective)
 }
+
+func (w *World) RefractedColor(comps Computation, remaining int) Color {
+	return w.refractedColor(comps, remaining, fixedRNG())
+}
+
+func (w *World) refractedColor(comps Computation, remaining int, rng *rand.Rand) Color {
+
+	if remaining <= 0 {
+		return NewColor(0, 0, 0)
+	}
+
+	if comps.o.GetMaterial().transparency == 0 {
+		return NewColor(0, 0, 0)
+	}
+
+	// Snell's law: check for total internal reflection before bending the ray
+	nRatio := comps.n1 / comps.n2
+	cosI := comps.eyev.Dot(comps.normalv)
+	sin2t := nRatio * nRatio * (1 - cosI*cosI)
+	if sin2t > 1 {
+		return NewColor(0, 0, 0)
+	}
+
+	cosT := math.Sqrt(1.0 - sin2t)
+	direction := comps.normalv.Scale(nRatio*cosI - cosT).Sub(comps.eyev.Scale(nRatio))
+	refractRay := Ray{origin: comps.underpoint, direction: direction}
+
+	color := w.colorAt(refractRay, remaining-1, rng)
+	return color.MultiplyByScalar(comps.o.GetMaterial().transparency)
+}
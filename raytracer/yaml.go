@@ -0,0 +1,400 @@
+package raytracer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// This file holds the small subset of YAML that scene files are written in:
+// block mappings and sequences nested by indentation, flow sequences and
+// mappings such as [translate, 1, 2, 3], plain and quoted scalars, and #
+// comments. Anchors, tags, multi-document streams and block scalars are not
+// supported.
+
+type yamlKind int
+
+const (
+	yamlScalar yamlKind = iota
+	yamlSequence
+	yamlMapping
+)
+
+// yamlNode is a parsed YAML value. A mapping keeps its keys in file order,
+// with keys[i], found on keyLines[i], naming items[i]; a sequence only uses
+// items.
+type yamlNode struct {
+	kind     yamlKind
+	line     int
+	value    string
+	keys     []string
+	keyLines []int
+	items    []*yamlNode
+}
+
+// get returns the value of key in a mapping, or nil when it is absent.
+func (n *yamlNode) get(key string) *yamlNode {
+	for i, k := range n.keys {
+		if k == key {
+			return n.items[i]
+		}
+	}
+	return nil
+}
+
+func (n *yamlNode) describe() string {
+	switch n.kind {
+	case yamlSequence:
+		return "a list"
+	case yamlMapping:
+		return "a mapping"
+	}
+	if n.value == "" {
+		return "nothing"
+	}
+	return fmt.Sprintf("%q", n.value)
+}
+
+type yamlLine struct {
+	number int
+	indent int
+	text   string
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// parseYAML reads a single YAML document from r. An empty document is an
+// empty scalar.
+func parseYAML(r io.Reader) (*yamlNode, error) {
+	lines, err := readYAMLLines(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return &yamlNode{kind: yamlScalar, line: 1}, nil
+	}
+
+	p := &yamlParser{lines: lines}
+	root, err := p.parseBlock(lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		return nil, yamlError(p.lines[p.pos].number, "unexpected indentation")
+	}
+	return root, nil
+}
+
+// readYAMLLines splits r into lines with comments and blank lines removed. A
+// flow collection left open at the end of a line is joined with the lines
+// that follow until it is closed.
+func readYAMLLines(r io.Reader) ([]yamlLine, error) {
+	var lines []yamlLine
+	scanner := bufio.NewScanner(r)
+	number := 0
+	depth := 0
+	for scanner.Scan() {
+		number++
+		raw := scanner.Text()
+		if strings.HasPrefix(raw, "---") || strings.HasPrefix(raw, "...") {
+			continue
+		}
+		if depth == 0 && strings.HasPrefix(strings.TrimLeft(raw, " "), "\t") {
+			return nil, yamlError(number, "tabs cannot be used for indentation")
+		}
+
+		text, d, err := stripYAMLComment(raw)
+		if err != nil {
+			return nil, yamlError(number, "%v", err)
+		}
+		if depth > 0 {
+			last := &lines[len(lines)-1]
+			last.text += " " + strings.TrimSpace(text)
+			depth += d
+			continue
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		trimmed := strings.TrimLeft(text, " ")
+		lines = append(lines, yamlLine{
+			number: number,
+			indent: len(text) - len(trimmed),
+			text:   strings.TrimRight(trimmed, " \t"),
+		})
+		depth = d
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if depth > 0 {
+		return nil, yamlError(lines[len(lines)-1].number, "unclosed [ or {")
+	}
+	return lines, nil
+}
+
+// stripYAMLComment removes a trailing comment from line and returns how many
+// more brackets it opens than it closes.
+func stripYAMLComment(line string) (string, int, error) {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i], depth, nil
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		}
+	}
+	if quote != 0 {
+		return "", 0, fmt.Errorf("unterminated quoted string")
+	}
+	return line, depth, nil
+}
+
+func yamlError(line int, format string, args ...any) error {
+	return fmt.Errorf("line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+func isSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// parseBlock parses the mapping or sequence whose entries start at indent.
+func (p *yamlParser) parseBlock(indent int) (*yamlNode, error) {
+	if isSequenceItem(p.lines[p.pos].text) {
+		return p.parseSequence(indent)
+	}
+	if _, _, ok := splitYAMLKey(p.lines[p.pos].text); ok {
+		return p.parseMapping(indent)
+	}
+	line := p.lines[p.pos]
+	p.pos++
+	return parseYAMLInline(line.text, line.number)
+}
+
+func (p *yamlParser) parseSequence(indent int) (*yamlNode, error) {
+	seq := &yamlNode{kind: yamlSequence, line: p.lines[p.pos].number}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isSequenceItem(p.lines[p.pos].text) {
+		line := &p.lines[p.pos]
+		rest := strings.TrimLeft(strings.TrimPrefix(line.text, "-"), " ")
+
+		var item *yamlNode
+		var err error
+		switch {
+		case rest == "":
+			item, err = p.parseNested(indent, line.number)
+		case isSequenceItem(rest) || isYAMLKeyLine(rest):
+			// "- key: value" starts a mapping, and "- - item" a sequence,
+			// whose entries line up with the text after the dash
+			line.indent += len(line.text) - len(rest)
+			line.text = rest
+			item, err = p.parseBlock(line.indent)
+		default:
+			p.pos++
+			item, err = parseYAMLInline(rest, line.number)
+		}
+		if err != nil {
+			return nil, err
+		}
+		seq.items = append(seq.items, item)
+	}
+	return seq, nil
+}
+
+func (p *yamlParser) parseMapping(indent int) (*yamlNode, error) {
+	m := &yamlNode{kind: yamlMapping, line: p.lines[p.pos].number}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent && !isSequenceItem(p.lines[p.pos].text) {
+		line := p.lines[p.pos]
+		key, rest, ok := splitYAMLKey(line.text)
+		if !ok {
+			return nil, yamlError(line.number, "expected \"key: value\", got %q", line.text)
+		}
+		if m.get(key) != nil {
+			return nil, yamlError(line.number, "duplicate key %q", key)
+		}
+
+		var value *yamlNode
+		var err error
+		if rest == "" {
+			value, err = p.parseNested(indent, line.number)
+		} else {
+			p.pos++
+			value, err = parseYAMLInline(rest, line.number)
+		}
+		if err != nil {
+			return nil, err
+		}
+		m.keys = append(m.keys, key)
+		m.keyLines = append(m.keyLines, line.number)
+		m.items = append(m.items, value)
+	}
+	return m, nil
+}
+
+// parseNested parses the value that follows a key or dash with nothing after
+// it: a block indented further than indent, a sequence at the same indent
+// (which YAML allows under a mapping key), or else nothing at all.
+func (p *yamlParser) parseNested(indent, lineNumber int) (*yamlNode, error) {
+	p.pos++
+	if p.pos < len(p.lines) {
+		next := p.lines[p.pos]
+		if next.indent > indent {
+			return p.parseBlock(next.indent)
+		}
+		if next.indent == indent && isSequenceItem(next.text) && !isSequenceItem(p.lines[p.pos-1].text) {
+			return p.parseSequence(indent)
+		}
+	}
+	return &yamlNode{kind: yamlScalar, line: lineNumber}, nil
+}
+
+// splitYAMLKey splits "key: value" at the first colon that is followed by a
+// space or ends the line and is not inside quotes or brackets.
+func splitYAMLKey(text string) (string, string, bool) {
+	if strings.HasPrefix(text, "[") || strings.HasPrefix(text, "{") {
+		return "", "", false
+	}
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ':' && (i+1 == len(text) || text[i+1] == ' '):
+			key := unquoteYAML(strings.TrimSpace(text[:i]))
+			return key, strings.TrimSpace(text[i+1:]), key != ""
+		}
+	}
+	return "", "", false
+}
+
+func isYAMLKeyLine(text string) bool {
+	_, _, ok := splitYAMLKey(text)
+	return ok
+}
+
+func unquoteYAML(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// parseYAMLInline parses a value written on one line: a flow collection or a
+// scalar.
+func parseYAMLInline(text string, lineNumber int) (*yamlNode, error) {
+	if !strings.HasPrefix(text, "[") && !strings.HasPrefix(text, "{") {
+		return &yamlNode{kind: yamlScalar, line: lineNumber, value: unquoteYAML(text)}, nil
+	}
+	f := &yamlFlow{text: text, line: lineNumber}
+	n, err := f.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	f.skipSpace()
+	if f.pos < len(f.text) {
+		return nil, yamlError(lineNumber, "unexpected %q after %s", f.text[f.pos:], n.describe())
+	}
+	return n, nil
+}
+
+// yamlFlow parses flow collections such as [1, [2, 3], {a: b}].
+type yamlFlow struct {
+	text string
+	pos  int
+	line int
+}
+
+func (f *yamlFlow) skipSpace() {
+	for f.pos < len(f.text) && f.text[f.pos] == ' ' {
+		f.pos++
+	}
+}
+
+func (f *yamlFlow) parseValue() (*yamlNode, error) {
+	f.skipSpace()
+	if f.pos < len(f.text) {
+		switch f.text[f.pos] {
+		case '[':
+			return f.parseCollection(']')
+		case '{':
+			return f.parseCollection('}')
+		}
+	}
+	return f.parseScalar(), nil
+}
+
+func (f *yamlFlow) parseScalar() *yamlNode {
+	start := f.pos
+	if f.pos < len(f.text) && (f.text[f.pos] == '"' || f.text[f.pos] == '\'') {
+		end := strings.IndexByte(f.text[f.pos+1:], f.text[f.pos])
+		if end >= 0 {
+			f.pos += end + 2
+			return &yamlNode{kind: yamlScalar, line: f.line, value: f.text[start+1 : f.pos-1]}
+		}
+	}
+	for f.pos < len(f.text) && !strings.ContainsRune(",]}", rune(f.text[f.pos])) &&
+		!(f.text[f.pos] == ':' && (f.pos+1 == len(f.text) || f.text[f.pos+1] == ' ')) {
+		f.pos++
+	}
+	return &yamlNode{kind: yamlScalar, line: f.line, value: strings.TrimSpace(f.text[start:f.pos])}
+}
+
+func (f *yamlFlow) parseCollection(closing byte) (*yamlNode, error) {
+	n := &yamlNode{kind: yamlSequence, line: f.line}
+	if closing == '}' {
+		n.kind = yamlMapping
+	}
+	f.pos++ // opening bracket
+
+	for {
+		f.skipSpace()
+		if f.pos >= len(f.text) {
+			return nil, yamlError(f.line, "missing %c", closing)
+		}
+		if f.text[f.pos] == closing {
+			f.pos++
+			return n, nil
+		}
+
+		if n.kind == yamlMapping {
+			key := f.parseScalar()
+			if f.pos >= len(f.text) || f.text[f.pos] != ':' {
+				return nil, yamlError(f.line, "expected \"key: value\" in {}")
+			}
+			f.pos++
+			n.keys = append(n.keys, key.value)
+			n.keyLines = append(n.keyLines, f.line)
+		}
+		item, err := f.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		n.items = append(n.items, item)
+
+		f.skipSpace()
+		if f.pos < len(f.text) && f.text[f.pos] == ',' {
+			f.pos++
+		} else if f.pos < len(f.text) && f.text[f.pos] != closing {
+			return nil, yamlError(f.line, "expected , or %c, got %q", closing, f.text[f.pos:])
+		}
+	}
+}
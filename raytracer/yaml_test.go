@@ -0,0 +1,79 @@
+package raytracer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	doc := `
+# leading comment
+- name: "quoted # not a comment"   # trailing comment
+  list:
+  - 1
+  - [a, [b, c], {d: e}]
+- - nested
+  - sequence
+- transform: [
+    [scale, 1, 2, 3],
+    [translate, 4, 5, 6]
+  ]
+  empty:
+`
+	root, err := parseYAML(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if root.kind != yamlSequence || len(root.items) != 3 {
+		t.Fatalf("Expected a list of 3 items, got %s with %d", root.describe(), len(root.items))
+	}
+
+	first := root.items[0]
+	if got := first.get("name").value; got != "quoted # not a comment" {
+		t.Errorf("Expected the quoted string, got %q", got)
+	}
+	list := first.get("list")
+	if list.kind != yamlSequence || len(list.items) != 2 || list.line != 5 {
+		t.Fatalf("Expected a list of 2 items starting on line 5, got %s on line %d", list.describe(), list.line)
+	}
+	flow := list.items[1]
+	if len(flow.items) != 3 || flow.items[1].items[1].value != "c" || flow.items[2].get("d").value != "e" {
+		t.Errorf("Expected [a, [b, c], {d: e}], got %+v", flow)
+	}
+
+	nested := root.items[1]
+	if nested.kind != yamlSequence || len(nested.items) != 2 || nested.items[1].value != "sequence" {
+		t.Errorf("Expected a nested sequence, got %+v", nested)
+	}
+
+	third := root.items[2]
+	transform := third.get("transform")
+	if len(transform.items) != 2 || transform.items[1].items[3].value != "6" || transform.line != 9 {
+		t.Errorf("Expected a flow list joined across lines, got %+v", transform)
+	}
+	if empty := third.get("empty"); empty == nil || empty.kind != yamlScalar || empty.value != "" {
+		t.Errorf("Expected an empty value, got %+v", empty)
+	}
+	if third.keyLines[1] != 13 {
+		t.Errorf("Expected the key on line 13, got %d", third.keyLines[1])
+	}
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	tests := []struct {
+		doc, message string
+	}{
+		{"a: 1\na: 2", "line 2: duplicate key \"a\""},
+		{"a:\n\t- 1", "line 2: tabs cannot be used for indentation"},
+		{"a: \"open", "line 1: unterminated quoted string"},
+		{"a: [1, 2", "line 1: unclosed [ or {"},
+		{"a: [1 2] 3", "line 1: unexpected \"3\" after a list"},
+		{"- 1\n- 2\nb: 3", "line 3: unexpected indentation"},
+	}
+	for _, tt := range tests {
+		_, err := parseYAML(strings.NewReader(tt.doc))
+		if err == nil || err.Error() != tt.message {
+			t.Errorf("%q: expected error %q, got %v", tt.doc, tt.message, err)
+		}
+	}
+}
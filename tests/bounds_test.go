@@ -0,0 +1,283 @@
+package tests
+
+import (
+	. "github.com/michaelzhao820/raytracer/raytracer"
+	"math"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestBoundingBox(t *testing.T) {
+	t.Run("Adding points to an empty bounding box", func(t *testing.T) {
+		box := EmptyBoundingBox().AddPoint(NewPoint(-5, 2, 0)).AddPoint(NewPoint(7, 0, -3))
+		if !box.Min.Equals(NewPoint(-5, 0, -3)) || !box.Max.Equals(NewPoint(7, 2, 0)) {
+			t.Errorf("Expected box (-5, 0, -3)-(7, 2, 0), got %v-%v", box.Min, box.Max)
+		}
+	})
+
+	t.Run("Merging one bounding box into another", func(t *testing.T) {
+		box1 := NewBoundingBox(NewPoint(-5, -2, 0), NewPoint(7, 4, 4))
+		box2 := NewBoundingBox(NewPoint(8, -7, -2), NewPoint(14, 2, 8))
+		box := box1.Merge(box2)
+		if !box.Min.Equals(NewPoint(-5, -7, -2)) || !box.Max.Equals(NewPoint(14, 4, 8)) {
+			t.Errorf("Expected box (-5, -7, -2)-(14, 4, 8), got %v-%v", box.Min, box.Max)
+		}
+	})
+
+	t.Run("Checking whether a box contains a point or box", func(t *testing.T) {
+		box := NewBoundingBox(NewPoint(5, -2, 0), NewPoint(11, 4, 7))
+		if !box.ContainsPoint(NewPoint(8, 1, 3)) || box.ContainsPoint(NewPoint(3, 0, 3)) {
+			t.Errorf("ContainsPoint gave the wrong answer")
+		}
+		if !box.ContainsBox(NewBoundingBox(NewPoint(6, -1, 1), NewPoint(10, 3, 6))) ||
+			box.ContainsBox(NewBoundingBox(NewPoint(4, -3, -1), NewPoint(10, 3, 6))) {
+			t.Errorf("ContainsBox gave the wrong answer")
+		}
+	})
+
+	t.Run("Transforming a bounding box", func(t *testing.T) {
+		box := NewBoundingBox(NewPoint(-1, -1, -1), NewPoint(1, 1, 1))
+		rx, _ := RotationXMatrix(math.Pi / 4)
+		ry, _ := RotationYMatrix(math.Pi / 4)
+		m, _ := rx.MultiplyMatrices(ry)
+
+		box2 := box.Transform(m)
+		expectedMin := NewPoint(-1.41421, -1.70710, -1.70710)
+		expectedMax := NewPoint(1.41421, 1.70710, 1.70710)
+		for i := X; i <= Z; i++ {
+			if math.Abs(box2.Min[i]-expectedMin[i]) > 1e-4 || math.Abs(box2.Max[i]-expectedMax[i]) > 1e-4 {
+				t.Fatalf("Expected %v-%v, got %v-%v", expectedMin, expectedMax, box2.Min, box2.Max)
+			}
+		}
+	})
+
+	t.Run("Transforming an infinite box keeps it infinite", func(t *testing.T) {
+		rz, _ := RotationZMatrix(math.Pi / 4)
+		box := NewPlane().Bounds().Transform(rz)
+		for i := X; i <= Z; i++ {
+			if math.IsNaN(box.Min[i]) || math.IsNaN(box.Max[i]) {
+				t.Fatalf("Expected no NaN in %v-%v", box.Min, box.Max)
+			}
+		}
+		if !box.IsInfinite() {
+			t.Errorf("Expected rotated plane bounds to be infinite")
+		}
+	})
+
+	t.Run("Intersecting a ray with a bounding box", func(t *testing.T) {
+		box := NewBoundingBox(NewPoint(5, -2, 0), NewPoint(11, 4, 7))
+		tests := []struct {
+			origin    Tuple
+			direction Tuple
+			result    bool
+		}{
+			{NewPoint(15, 1, 2), NewVector(-1, 0, 0), true},
+			{NewPoint(-5, -1, 4), NewVector(1, 0, 0), true},
+			{NewPoint(7, 6, 5), NewVector(0, -1, 0), true},
+			{NewPoint(9, 0, 12), NewVector(0, 0, -1), true},
+			{NewPoint(8, 2, 4), NewVector(1, 0, 0), true},
+			{NewPoint(9, -1, -8), NewVector(2, 4, 6), false},
+			{NewPoint(8, 3, -4), NewVector(6, 2, 4), false},
+			{NewPoint(12, 5, 4), NewVector(4, 6, 2), false},
+			{NewPoint(15, 1, 2), NewVector(1, 0, 0), false},
+		}
+		for _, tt := range tests {
+			direction, _ := tt.direction.Normalize()
+			if got := box.Intersects(NewRay(tt.origin, direction)); got != tt.result {
+				t.Errorf("Ray from %v towards %v: expected %v, got %v", tt.origin, tt.direction, tt.result, got)
+			}
+		}
+	})
+}
+
+func TestShapeBounds(t *testing.T) {
+	t.Run("Bounds of primitive shapes", func(t *testing.T) {
+		cyl := NewCylinder()
+		cyl.Minimum = -5
+		cyl.Maximum = 3
+		cone := NewCone()
+		cone.Minimum = -5
+		cone.Maximum = 3
+
+		tests := []struct {
+			name     string
+			shape    Shape
+			min, max Tuple
+		}{
+			{"sphere", NewSphere(), NewPoint(-1, -1, -1), NewPoint(1, 1, 1)},
+			{"cube", NewCube(), NewPoint(-1, -1, -1), NewPoint(1, 1, 1)},
+			{"cylinder", cyl, NewPoint(-1, -5, -1), NewPoint(1, 3, 1)},
+			{"cone", cone, NewPoint(-5, -5, -5), NewPoint(5, 3, 5)},
+			{"triangle", NewTriangle(NewPoint(-3, 7, 2), NewPoint(6, 2, -4), NewPoint(2, -1, -1)),
+				NewPoint(-3, -1, -4), NewPoint(6, 7, 2)},
+		}
+		for _, tt := range tests {
+			box := tt.shape.Bounds()
+			if !box.Min.Equals(tt.min) || !box.Max.Equals(tt.max) {
+				t.Errorf("%s: expected %v-%v, got %v-%v", tt.name, tt.min, tt.max, box.Min, box.Max)
+			}
+		}
+	})
+
+	t.Run("A group has a bounding box that contains its children", func(t *testing.T) {
+		s := NewSphere()
+		m, _ := TranslationMatrix(2, 5, -3)
+		sc, _ := ScalingMatrix(2, 2, 2)
+		m, _ = m.MultiplyMatrices(sc)
+		s.SetTransform(m)
+		c := NewCylinder()
+		c.Minimum = -2
+		c.Maximum = 2
+		m, _ = TranslationMatrix(-4, -1, 4)
+		sc, _ = ScalingMatrix(0.5, 1, 0.5)
+		m, _ = m.MultiplyMatrices(sc)
+		c.SetTransform(m)
+		g := NewGroup()
+		g.AddChild(s)
+		g.AddChild(c)
+
+		box := g.Bounds()
+		if !box.Min.Equals(NewPoint(-4.5, -3, -5)) || !box.Max.Equals(NewPoint(4, 7, 4.5)) {
+			t.Errorf("Expected (-4.5, -3, -5)-(4, 7, 4.5), got %v-%v", box.Min, box.Max)
+		}
+	})
+
+	t.Run("A CSG shape has a bounding box that contains its children", func(t *testing.T) {
+		right := NewSphere()
+		m, _ := TranslationMatrix(2, 3, 4)
+		right.SetTransform(m)
+		c := NewCSG(CSGDifference, NewSphere(), right)
+
+		box := c.Bounds()
+		if !box.Min.Equals(NewPoint(-1, -1, -1)) || !box.Max.Equals(NewPoint(3, 4, 5)) {
+			t.Errorf("Expected (-1, -1, -1)-(3, 4, 5), got %v-%v", box.Min, box.Max)
+		}
+	})
+}
+
+func TestBVH(t *testing.T) {
+	t.Run("A world with a BVH finds the same intersections as a linear search", func(t *testing.T) {
+		w := NewWorld()
+		mesh := triangleMesh(20)
+		w.AddObject(mesh)
+		w.AddObject(NewPlane())
+		sphere := NewSphere()
+		m, _ := TranslationMatrix(3, 1, 4)
+		sphere.SetTransform(m)
+		w.AddObject(sphere)
+
+		for _, r := range []Ray{
+			// lands exactly on a grid line, so it starts on the faces of
+			// some of the triangles' boxes
+			NewRay(NewPoint(0.3, 5, 0.7), NewVector(0, -1, 0)),
+			NewRay(NewPoint(-2, 3, -2), NewVector(0.5, -0.5, 0.7)),
+			NewRay(NewPoint(3, 1, -5), NewVector(0, 0, 1)),
+			NewRay(NewPoint(50, 50, 50), NewVector(0, 1, 0)),
+		} {
+			var linear []Intersection
+			for _, o := range mesh.GetChildren() {
+				linear = append(linear, o.Intersect(r)...)
+			}
+			linear = append(linear, w.GetObjects()[1].Intersect(r)...)
+			linear = append(linear, sphere.Intersect(r)...)
+			sort.Slice(linear, func(i, j int) bool { return linear[i].GetTime() < linear[j].GetTime() })
+
+			xs := w.IntersectWorld(r)
+			if len(xs) != len(linear) {
+				t.Fatalf("Expected %d intersections, got %d", len(linear), len(xs))
+			}
+			for i := range xs {
+				if xs[i] != linear[i] {
+					t.Errorf("xs[%d]: expected %v, got %v", i, linear[i], xs[i])
+				}
+			}
+		}
+	})
+
+	t.Run("The BVH is built once when intersected from several goroutines", func(t *testing.T) {
+		w := NewWorld()
+		mesh := triangleMesh(20)
+		w.AddObject(mesh)
+		r := NewRay(NewPoint(0.31, 5, 0.77), NewVector(0, -1, 0))
+
+		results := make([][]Intersection, 8)
+		var wg sync.WaitGroup
+		for i := range results {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i] = w.IntersectWorld(r)
+				mesh.Intersect(r)
+			}()
+		}
+		wg.Wait()
+
+		for i, xs := range results {
+			if len(xs) != 1 {
+				t.Errorf("goroutine %d: expected 1 intersection, got %d", i, len(xs))
+			}
+		}
+	})
+}
+
+// triangleMesh returns a group holding an n x n grid of bumpy squares, two
+// triangles each, spanning -1 to 1 in x and z.
+func triangleMesh(n int) *Group {
+	g := NewGroup()
+	height := func(i, j int) float64 {
+		return 0.1 * math.Sin(float64(i)) * math.Cos(float64(j))
+	}
+	point := func(i, j int) Tuple {
+		return NewPoint(-1+2*float64(i)/float64(n), height(i, j), -1+2*float64(j)/float64(n))
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			g.AddChild(NewTriangle(point(i, j), point(i+1, j), point(i+1, j+1)))
+			g.AddChild(NewTriangle(point(i, j), point(i+1, j+1), point(i, j+1)))
+		}
+	}
+	return g
+}
+
+func benchmarkRays() []Ray {
+	var rays []Ray
+	for i := 0; i < 4; i++ {
+		x := -1 + 2*(float64(i)+0.5)/4
+		direction, _ := NewVector(x*0.1, -1, 0.05).Normalize()
+		rays = append(rays, NewRay(NewPoint(x, 2, 0.3), direction))
+	}
+	return rays
+}
+
+// BenchmarkIntersectMeshLinear tests every triangle of a 20,000 triangle mesh,
+// which is how IntersectWorld used to work.
+func BenchmarkIntersectMeshLinear(b *testing.B) {
+	mesh := triangleMesh(100)
+	rays := benchmarkRays()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for _, r := range rays {
+			var xs []Intersection
+			for _, tri := range mesh.GetChildren() {
+				xs = append(xs, tri.Intersect(r)...)
+			}
+			sort.Slice(xs, func(i, j int) bool { return xs[i].GetTime() < xs[j].GetTime() })
+		}
+	}
+}
+
+// BenchmarkIntersectMeshBVH intersects the same mesh and rays through the
+// world's bounding volume hierarchy.
+func BenchmarkIntersectMeshBVH(b *testing.B) {
+	w := NewWorld()
+	w.AddObject(triangleMesh(100))
+	w.BuildBVH()
+	rays := benchmarkRays()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for _, r := range rays {
+			w.IntersectWorld(r)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package tests
+
+import (
+	. "github.com/michaelzhao820/raytracer/raytracer"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestCameraRender(t *testing.T) {
+	newCamera := func() *Camera {
+		c := NewCamera(11, 11, math.Pi/2)
+		c.SetTransform(ViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0)))
+		return c
+	}
+
+	t.Run("Rendering a world with a camera", func(t *testing.T) {
+		w := NewWorld()
+		w.DefaultWorld()
+
+		image := newCamera().Render(*w)
+
+		if image.GetWidth() != 11 || image.Height != 11 {
+			t.Fatalf("Expected an 11x11 image, got %dx%d", image.GetWidth(), image.Height)
+		}
+		expected := NewColor(0.38066, 0.047583, 0.2855)
+		if !image.PixelAt(5, 5).Equals(expected) {
+			t.Errorf("Expected pixel (5, 5) = %v, got %v", expected, image.PixelAt(5, 5))
+		}
+	})
+
+	t.Run("Rendering to a chosen file", func(t *testing.T) {
+		w := NewWorld()
+		w.DefaultWorld()
+		filename := filepath.Join(t.TempDir(), "view.ppm")
+
+		if err := newCamera().RenderToFile(*w, filename); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := os.Stat(filename); err != nil {
+			t.Errorf("Expected %s to exist: %v", filename, err)
+		}
+	})
+
+	t.Run("Rendering one world from several cameras at once", func(t *testing.T) {
+		w := NewWorld()
+		w.DefaultWorld()
+		g := NewGroup()
+		inner := NewGroup()
+		inner.AddChild(NewSphere())
+		g.AddChild(inner)
+		m, _ := TranslationMatrix(0, 0, 3)
+		g.SetTransform(m)
+		w.AddObject(g)
+		expected := newCamera().Render(*w)
+
+		images := make([]Canvas, 4)
+		var wg sync.WaitGroup
+		for i := range images {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				images[i] = newCamera().Render(*w)
+			}()
+		}
+		wg.Wait()
+
+		for i, image := range images {
+			if !image.PixelAt(5, 5).Equals(expected.PixelAt(5, 5)) {
+				t.Errorf("render %d: expected pixel (5, 5) = %v, got %v", i, expected.PixelAt(5, 5), image.PixelAt(5, 5))
+			}
+		}
+	})
+
+	t.Run("Saving to an unknown format fails", func(t *testing.T) {
+		image := NewCanvas(2, 2)
+		filename := filepath.Join(t.TempDir(), "view.xyz")
+		if err := image.Save(filename); err == nil {
+			t.Errorf("Expected an error for an unsupported format")
+		}
+	})
+}
+
+func TestCameraAdaptiveSampling(t *testing.T) {
+	w := NewWorld()
+	w.DefaultWorld()
+	newCamera := func() *Camera {
+		c := NewCamera(21, 21, math.Pi/2)
+		c.SetTransform(ViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0)))
+		return c
+	}
+	plain := newCamera().Render(*w)
+
+	contrasts := func(x, y int, threshold float64) bool {
+		color := plain.PixelAt(x, y)
+		for _, n := range [][2]int{{x - 1, y}, {x + 1, y}, {x, y - 1}, {x, y + 1}} {
+			if n[0] < 0 || n[0] >= 21 || n[1] < 0 || n[1] >= 21 {
+				continue
+			}
+			other := plain.PixelAt(n[0], n[1])
+			for i := R; i <= B; i++ {
+				if math.Abs(color[i]-other[i]) > threshold {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	t.Run("Only pixels that stand out from a neighbour are refined", func(t *testing.T) {
+		c := newCamera()
+		c.SetAdaptive(0.1, 2)
+		adaptive := c.Render(*w)
+
+		refined := 0
+		for y := 0; y < 21; y++ {
+			for x := 0; x < 21; x++ {
+				if plain.PixelAt(x, y).Equals(adaptive.PixelAt(x, y)) {
+					continue
+				}
+				refined++
+				if !contrasts(x, y, 0.1) {
+					t.Errorf("Pixel (%d, %d) was refined but does not contrast with its neighbours", x, y)
+				}
+			}
+		}
+		if refined == 0 {
+			t.Errorf("Expected the edge of the sphere to be refined")
+		}
+	})
+
+	t.Run("A threshold above every contrast leaves the image unchanged", func(t *testing.T) {
+		c := newCamera()
+		c.SetAdaptive(10, 3)
+		adaptive := c.Render(*w)
+
+		for y := 0; y < 21; y++ {
+			for x := 0; x < 21; x++ {
+				if !plain.PixelAt(x, y).Equals(adaptive.PixelAt(x, y)) {
+					t.Fatalf("Pixel (%d, %d): expected %v, got %v", x, y, plain.PixelAt(x, y), adaptive.PixelAt(x, y))
+				}
+			}
+		}
+	})
+}
+
+func TestCameraDepthOfField(t *testing.T) {
+	w := NewWorld()
+	w.DefaultWorld()
+	newCamera := func() *Camera {
+		c := NewCamera(21, 21, math.Pi/2)
+		c.SetTransform(ViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0)))
+		return c
+	}
+	pinhole := newCamera().Render(*w)
+
+	// how far the render is from the pinhole image, summed over every pixel
+	difference := func(image Canvas) float64 {
+		total := 0.0
+		for y := 0; y < 21; y++ {
+			for x := 0; x < 21; x++ {
+				a, b := pinhole.PixelAt(x, y), image.PixelAt(x, y)
+				for i := R; i <= B; i++ {
+					total += math.Abs(a[i] - b[i])
+				}
+			}
+		}
+		return total
+	}
+	render := func(focalDistance float64) Canvas {
+		c := newCamera()
+		c.SetAperture(0.25)
+		c.SetFocalDistance(focalDistance)
+		c.SetSamples(16)
+		return c.Render(*w)
+	}
+
+	inFocus := difference(render(4))
+	outOfFocus := difference(render(1))
+	if inFocus >= outOfFocus {
+		t.Errorf("Expected the sphere to be sharper in focus (%v) than out of focus (%v)", inFocus, outOfFocus)
+	}
+}
+
+func TestCameraProjections(t *testing.T) {
+	w := NewWorld()
+	w.DefaultWorld()
+	black := NewColor(0, 0, 0)
+
+	t.Run("An orthographic render does not shrink with distance", func(t *testing.T) {
+		render := func(distance float64) Canvas {
+			c := NewCamera(21, 21, math.Pi/2)
+			c.SetProjection(OrthographicProjection)
+			c.SetViewWidth(4)
+			c.SetTransform(ViewTransform(NewPoint(0, 0, -distance), NewPoint(0, 0, 0), NewVector(0, 1, 0)))
+			return c.Render(*w)
+		}
+		near, far := render(5), render(50)
+
+		for y := 0; y < 21; y++ {
+			for x := 0; x < 21; x++ {
+				if !near.PixelAt(x, y).Equals(far.PixelAt(x, y)) {
+					t.Fatalf("Pixel (%d, %d): near %v, far %v", x, y, near.PixelAt(x, y), far.PixelAt(x, y))
+				}
+			}
+		}
+		if near.PixelAt(10, 10).Equals(black) || !near.PixelAt(0, 10).Equals(black) {
+			t.Errorf("Expected the sphere to fill the middle half of the view")
+		}
+	})
+
+	t.Run("An equirectangular panorama sees objects behind the camera", func(t *testing.T) {
+		c := NewCamera(40, 20, math.Pi/2)
+		c.SetProjection(EquirectangularProjection)
+		// looking away from the spheres
+		c.SetTransform(ViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, -10), NewVector(0, 1, 0)))
+		image := c.Render(*w)
+
+		if !image.PixelAt(20, 10).Equals(black) {
+			t.Errorf("Expected nothing straight ahead, got %v", image.PixelAt(20, 10))
+		}
+		if image.PixelAt(0, 10).Equals(black) {
+			t.Errorf("Expected the spheres behind the camera at the edge of the panorama")
+		}
+	})
+}
@@ -0,0 +1,162 @@
+package tests
+
+import (
+	. "github.com/michaelzhao820/raytracer/raytracer"
+	"testing"
+)
+
+func TestCSG(t *testing.T) {
+	t.Run("CSG is created with an operation and two shapes", func(t *testing.T) {
+		s1 := NewSphere()
+		s2 := NewCube()
+		c := NewCSG(CSGUnion, s1, s2)
+
+		if c.GetOperation() != CSGUnion {
+			t.Errorf("Expected union operation, got %v", c.GetOperation())
+		}
+		if c.GetLeft() != s1 || c.GetRight() != s2 {
+			t.Errorf("Expected left = s1 and right = s2")
+		}
+		if s1.GetParent() != c || s2.GetParent() != c {
+			t.Errorf("Expected both operands to have the CSG as parent")
+		}
+	})
+
+	t.Run("Evaluating the rule for a CSG operation", func(t *testing.T) {
+		tests := []struct {
+			op                       CSGOperation
+			leftHit, inLeft, inRight bool
+			result                   bool
+		}{
+			{CSGUnion, true, true, true, false},
+			{CSGUnion, true, true, false, true},
+			{CSGUnion, true, false, true, false},
+			{CSGUnion, true, false, false, true},
+			{CSGUnion, false, true, true, false},
+			{CSGUnion, false, true, false, false},
+			{CSGUnion, false, false, true, true},
+			{CSGUnion, false, false, false, true},
+			{CSGIntersection, true, true, true, true},
+			{CSGIntersection, true, true, false, false},
+			{CSGIntersection, true, false, true, true},
+			{CSGIntersection, true, false, false, false},
+			{CSGIntersection, false, true, true, true},
+			{CSGIntersection, false, true, false, true},
+			{CSGIntersection, false, false, true, false},
+			{CSGIntersection, false, false, false, false},
+			{CSGDifference, true, true, true, false},
+			{CSGDifference, true, true, false, true},
+			{CSGDifference, true, false, true, false},
+			{CSGDifference, true, false, false, true},
+			{CSGDifference, false, true, true, true},
+			{CSGDifference, false, true, false, true},
+			{CSGDifference, false, false, true, false},
+			{CSGDifference, false, false, false, false},
+		}
+
+		for _, tt := range tests {
+			got := IntersectionAllowed(tt.op, tt.leftHit, tt.inLeft, tt.inRight)
+			if got != tt.result {
+				t.Errorf("IntersectionAllowed(%v, %v, %v, %v) = %v, want %v",
+					tt.op, tt.leftHit, tt.inLeft, tt.inRight, got, tt.result)
+			}
+		}
+	})
+
+	t.Run("Filtering a list of intersections", func(t *testing.T) {
+		tests := []struct {
+			op     CSGOperation
+			x0, x1 int
+		}{
+			{CSGUnion, 0, 3},
+			{CSGIntersection, 1, 2},
+			{CSGDifference, 0, 1},
+		}
+
+		for _, tt := range tests {
+			s1 := NewSphere()
+			s2 := NewCube()
+			c := NewCSG(tt.op, s1, s2)
+			xs := Intersections(
+				NewIntersection(1, s1),
+				NewIntersection(2, s2),
+				NewIntersection(3, s1),
+				NewIntersection(4, s2),
+			)
+
+			result := c.FilterIntersections(xs)
+			if len(result) != 2 {
+				t.Fatalf("op %v: expected 2 intersections, got %d", tt.op, len(result))
+			}
+			if result[0] != xs[tt.x0] || result[1] != xs[tt.x1] {
+				t.Errorf("op %v: expected xs[%d] and xs[%d], got %v", tt.op, tt.x0, tt.x1, result)
+			}
+		}
+	})
+
+	t.Run("A ray misses a CSG object", func(t *testing.T) {
+		c := NewCSG(CSGUnion, NewSphere(), NewCube())
+		xs := c.Intersect(NewRay(NewPoint(0, 2, -5), NewVector(0, 0, 1)))
+		if len(xs) != 0 {
+			t.Errorf("Expected 0 intersections, got %d", len(xs))
+		}
+	})
+
+	t.Run("A ray hits a CSG object", func(t *testing.T) {
+		s1 := NewSphere()
+		s2 := NewSphere()
+		tm, _ := TranslationMatrix(0, 0, 0.5)
+		s2.SetTransform(tm)
+		c := NewCSG(CSGUnion, s1, s2)
+
+		xs := c.Intersect(NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1)))
+		if len(xs) != 2 {
+			t.Fatalf("Expected 2 intersections, got %d", len(xs))
+		}
+		if !almostEqual(xs[0].GetTime(), 4) || xs[0].GetObject() != s1 {
+			t.Errorf("Expected xs[0] = 4 on s1, got %v on %v", xs[0].GetTime(), xs[0].GetObject())
+		}
+		if !almostEqual(xs[1].GetTime(), 6.5) || xs[1].GetObject() != s2 {
+			t.Errorf("Expected xs[1] = 6.5 on s2, got %v on %v", xs[1].GetTime(), xs[1].GetObject())
+		}
+	})
+
+	t.Run("Includes looks inside groups and nested CSGs", func(t *testing.T) {
+		s1 := NewSphere()
+		s2 := NewCube()
+		g := NewGroup()
+		g.AddChild(s1)
+		c := NewCSG(CSGDifference, g, s2)
+
+		if !Includes(c, s1) || !Includes(g, s1) || !Includes(c, s2) {
+			t.Errorf("Expected CSG and group to include their descendants")
+		}
+		if Includes(g, s2) {
+			t.Errorf("Expected group not to include s2")
+		}
+	})
+
+	t.Run("A difference carves a hole through a shape", func(t *testing.T) {
+		// CSG tracks inside/outside by counting crossings, so the cutting
+		// shape has to be closed
+		cyl := NewCylinder()
+		cyl.Minimum = -2
+		cyl.Maximum = 2
+		cyl.Closed = true
+		sm, _ := ScalingMatrix(0.5, 1, 0.5)
+		cyl.SetTransform(sm)
+		c := NewCSG(CSGDifference, NewCube(), cyl)
+
+		// straight down the hole
+		xs := c.Intersect(NewRay(NewPoint(0, 5, 0), NewVector(0, -1, 0)))
+		if len(xs) != 0 {
+			t.Errorf("Expected ray through the hole to miss, got %d intersections", len(xs))
+		}
+
+		// through the wall beside the hole
+		xs = c.Intersect(NewRay(NewPoint(0.75, 5, 0), NewVector(0, -1, 0)))
+		if len(xs) != 2 {
+			t.Errorf("Expected 2 intersections, got %d", len(xs))
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package tests
+
+import (
+	. "github.com/michaelzhao820/raytracer/raytracer"
+	"testing"
+)
+
+func TestCubeIntersect(t *testing.T) {
+	t.Run("A ray intersects a cube", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			origin    Tuple
+			direction Tuple
+			t1, t2    float64
+		}{
+			{"+x", NewPoint(5, 0.5, 0), NewVector(-1, 0, 0), 4, 6},
+			{"-x", NewPoint(-5, 0.5, 0), NewVector(1, 0, 0), 4, 6},
+			{"+y", NewPoint(0.5, 5, 0), NewVector(0, -1, 0), 4, 6},
+			{"-y", NewPoint(0.5, -5, 0), NewVector(0, 1, 0), 4, 6},
+			{"+z", NewPoint(0.5, 0, 5), NewVector(0, 0, -1), 4, 6},
+			{"-z", NewPoint(0.5, 0, -5), NewVector(0, 0, 1), 4, 6},
+			{"inside", NewPoint(0, 0.5, 0), NewVector(0, 0, 1), -1, 1},
+		}
+
+		c := NewCube()
+		for _, tt := range tests {
+			xs := c.Intersect(NewRay(tt.origin, tt.direction))
+			if len(xs) != 2 {
+				t.Fatalf("%s: expected 2 intersections, got %d", tt.name, len(xs))
+			}
+			if !almostEqual(xs[0].GetTime(), tt.t1) || !almostEqual(xs[1].GetTime(), tt.t2) {
+				t.Errorf("%s: expected t = %v, %v, got %v, %v", tt.name, tt.t1, tt.t2,
+					xs[0].GetTime(), xs[1].GetTime())
+			}
+		}
+	})
+
+	t.Run("A ray misses a cube", func(t *testing.T) {
+		tests := []struct {
+			origin    Tuple
+			direction Tuple
+		}{
+			{NewPoint(-2, 0, 0), NewVector(0.2673, 0.5345, 0.8018)},
+			{NewPoint(0, -2, 0), NewVector(0.8018, 0.2673, 0.5345)},
+			{NewPoint(0, 0, -2), NewVector(0.5345, 0.8018, 0.2673)},
+			{NewPoint(2, 0, 2), NewVector(0, 0, -1)},
+			{NewPoint(0, 2, 2), NewVector(0, -1, 0)},
+			{NewPoint(2, 2, 0), NewVector(-1, 0, 0)},
+		}
+
+		c := NewCube()
+		for _, tt := range tests {
+			xs := c.Intersect(NewRay(tt.origin, tt.direction))
+			if len(xs) != 0 {
+				t.Errorf("Expected ray from %v to miss, got %d intersections", tt.origin, len(xs))
+			}
+		}
+	})
+
+	t.Run("Intersecting a transformed cube", func(t *testing.T) {
+		c := NewCube()
+		tm, _ := TranslationMatrix(5, 0, 0)
+		c.SetTransform(tm)
+
+		xs := c.Intersect(NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1)))
+		if len(xs) != 0 {
+			t.Errorf("Expected 0 intersections, got %d", len(xs))
+		}
+	})
+}
+
+func TestCubeNormal(t *testing.T) {
+	tests := []struct {
+		point    Tuple
+		expected Tuple
+	}{
+		{NewPoint(1, 0.5, -0.8), NewVector(1, 0, 0)},
+		{NewPoint(-1, -0.2, 0.9), NewVector(-1, 0, 0)},
+		{NewPoint(-0.4, 1, -0.1), NewVector(0, 1, 0)},
+		{NewPoint(0.3, -1, -0.7), NewVector(0, -1, 0)},
+		{NewPoint(-0.6, 0.3, 1), NewVector(0, 0, 1)},
+		{NewPoint(0.4, 0.4, -1), NewVector(0, 0, -1)},
+		{NewPoint(1, 1, 1), NewVector(1, 0, 0)},
+		{NewPoint(-1, -1, -1), NewVector(-1, 0, 0)},
+	}
+
+	c := NewCube()
+	for _, tt := range tests {
+		normal := c.NormalAt(tt.point)
+		if !normal.Equals(tt.expected) {
+			t.Errorf("Normal at %v: expected %v, got %v", tt.point, tt.expected, normal)
+		}
+	}
+}
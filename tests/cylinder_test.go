@@ -0,0 +1,243 @@
+package tests
+
+import (
+	. "github.com/michaelzhao820/raytracer/raytracer"
+	"math"
+	"testing"
+)
+
+func TestCylinderIntersect(t *testing.T) {
+	t.Run("A ray misses a cylinder", func(t *testing.T) {
+		tests := []struct {
+			origin    Tuple
+			direction Tuple
+		}{
+			{NewPoint(1, 0, 0), NewVector(0, 1, 0)},
+			{NewPoint(0, 0, 0), NewVector(0, 1, 0)},
+			{NewPoint(0, 0, -5), NewVector(1, 1, 1)},
+		}
+
+		cyl := NewCylinder()
+		for _, tt := range tests {
+			direction, _ := tt.direction.Normalize()
+			xs := cyl.Intersect(NewRay(tt.origin, direction))
+			if len(xs) != 0 {
+				t.Errorf("Expected ray from %v to miss, got %d intersections", tt.origin, len(xs))
+			}
+		}
+	})
+
+	t.Run("A ray strikes a cylinder", func(t *testing.T) {
+		tests := []struct {
+			origin    Tuple
+			direction Tuple
+			t0, t1    float64
+		}{
+			{NewPoint(1, 0, -5), NewVector(0, 0, 1), 5, 5},
+			{NewPoint(0, 0, -5), NewVector(0, 0, 1), 4, 6},
+			{NewPoint(0.5, 0, -5), NewVector(0.1, 1, 1), 6.80798, 7.08872},
+		}
+
+		cyl := NewCylinder()
+		for _, tt := range tests {
+			direction, _ := tt.direction.Normalize()
+			xs := cyl.Intersect(NewRay(tt.origin, direction))
+			if len(xs) != 2 {
+				t.Fatalf("Expected 2 intersections from %v, got %d", tt.origin, len(xs))
+			}
+			if math.Abs(xs[0].GetTime()-tt.t0) > 1e-4 || math.Abs(xs[1].GetTime()-tt.t1) > 1e-4 {
+				t.Errorf("Expected t = %v, %v, got %v, %v", tt.t0, tt.t1, xs[0].GetTime(), xs[1].GetTime())
+			}
+		}
+	})
+
+	t.Run("Intersecting a constrained cylinder", func(t *testing.T) {
+		tests := []struct {
+			point     Tuple
+			direction Tuple
+			count     int
+		}{
+			{NewPoint(0, 1.5, 0), NewVector(0.1, 1, 0), 0},
+			{NewPoint(0, 3, -5), NewVector(0, 0, 1), 0},
+			{NewPoint(0, 0, -5), NewVector(0, 0, 1), 0},
+			{NewPoint(0, 2, -5), NewVector(0, 0, 1), 0},
+			{NewPoint(0, 1, -5), NewVector(0, 0, 1), 0},
+			{NewPoint(0, 1.5, -2), NewVector(0, 0, 1), 2},
+		}
+
+		cyl := NewCylinder()
+		cyl.Minimum = 1
+		cyl.Maximum = 2
+		for i, tt := range tests {
+			direction, _ := tt.direction.Normalize()
+			xs := cyl.Intersect(NewRay(tt.point, direction))
+			if len(xs) != tt.count {
+				t.Errorf("Case %d: expected %d intersections, got %d", i+1, tt.count, len(xs))
+			}
+		}
+	})
+
+	t.Run("Intersecting the caps of a closed cylinder", func(t *testing.T) {
+		tests := []struct {
+			point     Tuple
+			direction Tuple
+			count     int
+		}{
+			{NewPoint(0, 3, 0), NewVector(0, -1, 0), 2},
+			{NewPoint(0, 3, -2), NewVector(0, -1, 2), 2},
+			{NewPoint(0, 4, -2), NewVector(0, -1, 1), 2},
+			{NewPoint(0, 0, -2), NewVector(0, 1, 2), 2},
+			{NewPoint(0, -1, -2), NewVector(0, 1, 1), 2},
+		}
+
+		cyl := NewCylinder()
+		cyl.Minimum = 1
+		cyl.Maximum = 2
+		cyl.Closed = true
+		for i, tt := range tests {
+			direction, _ := tt.direction.Normalize()
+			xs := cyl.Intersect(NewRay(tt.point, direction))
+			if len(xs) != tt.count {
+				t.Errorf("Case %d: expected %d intersections, got %d", i+1, tt.count, len(xs))
+			}
+		}
+	})
+}
+
+func TestCylinderNormal(t *testing.T) {
+	t.Run("Normal vector on a cylinder", func(t *testing.T) {
+		tests := []struct {
+			point    Tuple
+			expected Tuple
+		}{
+			{NewPoint(1, 0, 0), NewVector(1, 0, 0)},
+			{NewPoint(0, 5, -1), NewVector(0, 0, -1)},
+			{NewPoint(0, -2, 1), NewVector(0, 0, 1)},
+			{NewPoint(-1, 1, 0), NewVector(-1, 0, 0)},
+		}
+
+		cyl := NewCylinder()
+		for _, tt := range tests {
+			normal := cyl.NormalAt(tt.point)
+			if !normal.Equals(tt.expected) {
+				t.Errorf("Normal at %v: expected %v, got %v", tt.point, tt.expected, normal)
+			}
+		}
+	})
+
+	t.Run("The normal vector on a cylinder's end caps", func(t *testing.T) {
+		tests := []struct {
+			point    Tuple
+			expected Tuple
+		}{
+			{NewPoint(0, 1, 0), NewVector(0, -1, 0)},
+			{NewPoint(0.5, 1, 0), NewVector(0, -1, 0)},
+			{NewPoint(0, 1, 0.5), NewVector(0, -1, 0)},
+			{NewPoint(0, 2, 0), NewVector(0, 1, 0)},
+			{NewPoint(0.5, 2, 0), NewVector(0, 1, 0)},
+			{NewPoint(0, 2, 0.5), NewVector(0, 1, 0)},
+		}
+
+		cyl := NewCylinder()
+		cyl.Minimum = 1
+		cyl.Maximum = 2
+		cyl.Closed = true
+		for _, tt := range tests {
+			normal := cyl.NormalAt(tt.point)
+			if !normal.Equals(tt.expected) {
+				t.Errorf("Normal at %v: expected %v, got %v", tt.point, tt.expected, normal)
+			}
+		}
+	})
+}
+
+func TestCylinderDefaults(t *testing.T) {
+	cyl := NewCylinder()
+	if !math.IsInf(cyl.Minimum, -1) || !math.IsInf(cyl.Maximum, 1) {
+		t.Errorf("Expected cylinder to be unbounded, got minimum %v, maximum %v", cyl.Minimum, cyl.Maximum)
+	}
+	if cyl.Closed {
+		t.Errorf("Expected cylinder to be open by default")
+	}
+}
+
+func TestConeIntersect(t *testing.T) {
+	t.Run("Intersecting a cone with a ray", func(t *testing.T) {
+		tests := []struct {
+			origin    Tuple
+			direction Tuple
+			t0, t1    float64
+		}{
+			{NewPoint(0, 0, -5), NewVector(0, 0, 1), 5, 5},
+			{NewPoint(0, 0, -5), NewVector(1, 1, 1), 8.66025, 8.66025},
+			{NewPoint(1, 1, -5), NewVector(-0.5, -1, 1), 4.55006, 49.44994},
+		}
+
+		shape := NewCone()
+		for _, tt := range tests {
+			direction, _ := tt.direction.Normalize()
+			xs := shape.Intersect(NewRay(tt.origin, direction))
+			if len(xs) != 2 {
+				t.Fatalf("Expected 2 intersections from %v, got %d", tt.origin, len(xs))
+			}
+			if math.Abs(xs[0].GetTime()-tt.t0) > 1e-4 || math.Abs(xs[1].GetTime()-tt.t1) > 1e-4 {
+				t.Errorf("Expected t = %v, %v, got %v, %v", tt.t0, tt.t1, xs[0].GetTime(), xs[1].GetTime())
+			}
+		}
+	})
+
+	t.Run("Intersecting a cone with a ray parallel to one of its halves", func(t *testing.T) {
+		shape := NewCone()
+		direction, _ := NewVector(0, 1, 1).Normalize()
+		xs := shape.Intersect(NewRay(NewPoint(0, 0, -1), direction))
+		if len(xs) != 1 {
+			t.Fatalf("Expected 1 intersection, got %d", len(xs))
+		}
+		if math.Abs(xs[0].GetTime()-0.35355) > 1e-4 {
+			t.Errorf("Expected t = 0.35355, got %v", xs[0].GetTime())
+		}
+	})
+
+	t.Run("Intersecting a cone's end caps", func(t *testing.T) {
+		tests := []struct {
+			origin    Tuple
+			direction Tuple
+			count     int
+		}{
+			{NewPoint(0, 0, -5), NewVector(0, 1, 0), 0},
+			{NewPoint(0, 0, -0.25), NewVector(0, 1, 1), 2},
+			{NewPoint(0, 0, -0.25), NewVector(0, 1, 0), 4},
+		}
+
+		shape := NewCone()
+		shape.Minimum = -0.5
+		shape.Maximum = 0.5
+		shape.Closed = true
+		for i, tt := range tests {
+			direction, _ := tt.direction.Normalize()
+			xs := shape.Intersect(NewRay(tt.origin, direction))
+			if len(xs) != tt.count {
+				t.Errorf("Case %d: expected %d intersections, got %d", i+1, tt.count, len(xs))
+			}
+		}
+	})
+}
+
+func TestConeNormal(t *testing.T) {
+	tests := []struct {
+		point    Tuple
+		expected Tuple
+	}{
+		{NewPoint(1, 1, 1), NewVector(1, -math.Sqrt2, 1)},
+		{NewPoint(-1, -1, 0), NewVector(-1, 1, 0)},
+	}
+
+	shape := NewCone()
+	for _, tt := range tests {
+		expected, _ := tt.expected.Normalize()
+		normal := shape.NormalAt(tt.point)
+		if !normal.Equals(expected) {
+			t.Errorf("Normal at %v: expected %v, got %v", tt.point, expected, normal)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package tests
+
+import (
+	. "github.com/michaelzhao820/raytracer/raytracer"
+	"math"
+	"testing"
+)
+
+func TestFilters(t *testing.T) {
+	t.Run("A box filter weights the whole pixel equally", func(t *testing.T) {
+		f := NewBoxFilter()
+		if f.Radius() != 0.5 {
+			t.Errorf("Expected radius 0.5, got %v", f.Radius())
+		}
+		if f.Weight(0, 0) != f.Weight(0.49, -0.49) {
+			t.Errorf("Expected equal weights, got %v and %v", f.Weight(0, 0), f.Weight(0.49, -0.49))
+		}
+	})
+
+	t.Run("A tent filter falls off linearly to its radius", func(t *testing.T) {
+		f := NewTentFilter(2)
+		tests := []struct {
+			dx, dy, expected float64
+		}{
+			{0, 0, 4},
+			{1, 0, 2},
+			{1, -1, 1},
+			{2, 0, 0},
+			{0, 3, 0},
+		}
+		for _, tt := range tests {
+			if got := f.Weight(tt.dx, tt.dy); !almostEqual(got, tt.expected) {
+				t.Errorf("Expected weight %v at (%v, %v), got %v", tt.expected, tt.dx, tt.dy, got)
+			}
+		}
+	})
+
+	t.Run("A Gaussian filter peaks at the centre and reaches zero at its radius", func(t *testing.T) {
+		f := NewGaussianFilter(1.5, 2)
+		centre := math.Pow(1-math.Exp(-2*1.5*1.5), 2)
+		if got := f.Weight(0, 0); !almostEqual(got, centre) {
+			t.Errorf("Expected weight %v at the centre, got %v", centre, got)
+		}
+		if f.Weight(0.5, 0) >= f.Weight(0, 0) {
+			t.Errorf("Expected the weight to fall off away from the centre")
+		}
+		if got := f.Weight(1.5, 0); got != 0 {
+			t.Errorf("Expected zero weight at the radius, got %v", got)
+		}
+	})
+
+	t.Run("Filters that would weight every sample zero use their defaults", func(t *testing.T) {
+		if r := NewTentFilter(0).Radius(); r != 1 {
+			t.Errorf("Expected a tent radius of 0 to be treated as 1, got %v", r)
+		}
+		if r := NewGaussianFilter(-1, 2).Radius(); r != 1.5 {
+			t.Errorf("Expected a Gaussian radius of -1 to be treated as 1.5, got %v", r)
+		}
+		if w := NewGaussianFilter(1.5, 0).Weight(0, 0); w <= 0 {
+			t.Errorf("Expected a Gaussian with alpha 0 to weight its centre, got %v", w)
+		}
+	})
+}
+
+func TestCameraSupersampling(t *testing.T) {
+	w := NewWorld()
+	w.DefaultWorld()
+	c := NewCamera(11, 11, math.Pi/2)
+	c.SetTransform(ViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0)))
+
+	single := c.Render(*w)
+	c.SetSamples(16)
+	smooth := c.Render(*w)
+
+	// a pixel whose centre misses the sphere should pick up some of its colour
+	// when the sphere covers part of the pixel
+	black := NewColor(0, 0, 0)
+	for y := 0; y < 11; y++ {
+		for x := 0; x < 11; x++ {
+			if single.PixelAt(x, y).Equals(black) && !smooth.PixelAt(x, y).Equals(black) {
+				return
+			}
+		}
+	}
+	t.Errorf("Expected supersampling to soften the edge of the sphere")
+}
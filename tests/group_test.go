@@ -0,0 +1,156 @@
+package tests
+
+import (
+	. "github.com/michaelzhao820/raytracer/raytracer"
+	"math"
+	"testing"
+)
+
+func TestGroup(t *testing.T) {
+	t.Run("Creating a new group", func(t *testing.T) {
+		g := NewGroup()
+		if !g.GetTransformMatrix().Equals(IdentityMatrix()) {
+			t.Errorf("Expected identity transform, got %v", g.GetTransformMatrix())
+		}
+		if len(g.GetChildren()) != 0 {
+			t.Errorf("Expected empty group, got %d children", len(g.GetChildren()))
+		}
+	})
+
+	t.Run("A shape has no parent by default", func(t *testing.T) {
+		s := NewSphere()
+		if s.GetParent() != nil {
+			t.Errorf("Expected no parent, got %v", s.GetParent())
+		}
+	})
+
+	t.Run("Adding a child to a group", func(t *testing.T) {
+		g := NewGroup()
+		s := NewSphere()
+		g.AddChild(s)
+
+		if len(g.GetChildren()) != 1 || g.GetChildren()[0] != s {
+			t.Errorf("Expected group to contain s")
+		}
+		if s.GetParent() != g {
+			t.Errorf("Expected s.parent = g")
+		}
+	})
+
+	t.Run("Intersecting a ray with an empty group", func(t *testing.T) {
+		g := NewGroup()
+		xs := g.Intersect(NewRay(NewPoint(0, 0, 0), NewVector(0, 0, 1)))
+		if len(xs) != 0 {
+			t.Errorf("Expected 0 intersections, got %d", len(xs))
+		}
+	})
+
+	t.Run("Intersecting a ray with a nonempty group", func(t *testing.T) {
+		g := NewGroup()
+		s1 := NewSphere()
+		s2 := NewSphere()
+		tm, _ := TranslationMatrix(0, 0, -3)
+		s2.SetTransform(tm)
+		s3 := NewSphere()
+		tm, _ = TranslationMatrix(5, 0, 0)
+		s3.SetTransform(tm)
+		g.AddChild(s1)
+		g.AddChild(s2)
+		g.AddChild(s3)
+
+		xs := g.Intersect(NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1)))
+		if len(xs) != 4 {
+			t.Fatalf("Expected 4 intersections, got %d", len(xs))
+		}
+		expected := []Shape{s2, s2, s1, s1}
+		for i, o := range expected {
+			if xs[i].GetObject() != o {
+				t.Errorf("Expected xs[%d] to hit %v, got %v", i, o, xs[i].GetObject())
+			}
+		}
+	})
+
+	t.Run("Intersecting a transformed group", func(t *testing.T) {
+		g := NewGroup()
+		sm, _ := ScalingMatrix(2, 2, 2)
+		g.SetTransform(sm)
+		s := NewSphere()
+		tm, _ := TranslationMatrix(5, 0, 0)
+		s.SetTransform(tm)
+		g.AddChild(s)
+
+		xs := g.Intersect(NewRay(NewPoint(10, 0, -10), NewVector(0, 0, 1)))
+		if len(xs) != 2 {
+			t.Errorf("Expected 2 intersections, got %d", len(xs))
+		}
+	})
+}
+
+func TestGroupTransforms(t *testing.T) {
+	newNested := func(sx, sy, sz float64) *Sphere {
+		g1 := NewGroup()
+		rm, _ := RotationYMatrix(math.Pi / 2)
+		g1.SetTransform(rm)
+		g2 := NewGroup()
+		sm, _ := ScalingMatrix(sx, sy, sz)
+		g2.SetTransform(sm)
+		g1.AddChild(g2)
+		s := NewSphere()
+		tm, _ := TranslationMatrix(5, 0, 0)
+		s.SetTransform(tm)
+		g2.AddChild(s)
+		return s
+	}
+
+	t.Run("Converting a point from world to object space", func(t *testing.T) {
+		s := newNested(2, 2, 2)
+		p := WorldToObject(s, NewPoint(-2, 0, -10))
+
+		expected := NewPoint(0, 0, -1)
+		if !p.Equals(expected) {
+			t.Errorf("Expected %v, got %v", expected, p)
+		}
+	})
+
+	t.Run("Converting a normal from object to world space", func(t *testing.T) {
+		s := newNested(1, 2, 3)
+		v := math.Sqrt(3) / 3
+		n := NormalToWorld(s, NewVector(v, v, v))
+
+		expected := NewVector(0.2857, 0.4286, -0.8571)
+		for i := X; i <= Z; i++ {
+			if math.Abs(n[i]-expected[i]) > 1e-4 {
+				t.Fatalf("Expected %v, got %v", expected, n)
+			}
+		}
+	})
+
+	t.Run("Finding the normal on a child object", func(t *testing.T) {
+		s := newNested(1, 2, 3)
+		n := s.NormalAt(NewPoint(1.7321, 1.1547, -5.5774))
+
+		expected := NewVector(0.2857, 0.4286, -0.8571)
+		for i := X; i <= Z; i++ {
+			if math.Abs(n[i]-expected[i]) > 1e-4 {
+				t.Fatalf("Expected %v, got %v", expected, n)
+			}
+		}
+	})
+
+	t.Run("A pattern on a child object honours the group transform", func(t *testing.T) {
+		g := NewGroup()
+		sm, _ := ScalingMatrix(2, 2, 2)
+		g.SetTransform(sm)
+		s := NewSphere()
+		g.AddChild(s)
+
+		white := NewColor(1, 1, 1)
+		black := NewColor(0, 0, 0)
+		pattern := NewStripePattern(white, black)
+
+		c := pattern.PatternAtObject(s, Point4(1.5, 0, 0))
+		if !c.Equals(white) {
+			t.Errorf("Expected white, got %v", c)
+		}
+	})
+}
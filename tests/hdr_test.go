@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/binary"
+	. "github.com/michaelzhao820/raytracer/raytracer"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteHDR(t *testing.T) {
+	c := NewCanvas(3, 2)
+	c.WritePixel(0, 0, NewColor(1, 0.5, 0.25))
+	c.WritePixel(1, 0, NewColor(12, 3, 0))
+	c.WritePixel(2, 0, NewColor(-1, 0.0001, 0))
+	c.WritePixel(0, 1, NewColor(0, 0, 0))
+
+	var buf bytes.Buffer
+	if err := c.WriteHDR(&buf); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	header := "#?RADIANCE\nFORMAT=32-bit_rle_rgbe\n\n-Y 2 +X 3\n"
+	data := buf.String()
+
+	t.Run("The header gives the format and resolution", func(t *testing.T) {
+		if !strings.HasPrefix(data, header) {
+			t.Errorf("Expected header %q, got %q", header, data[:len(header)])
+		}
+		if len(data) != len(header)+3*2*4 {
+			t.Errorf("Expected %d bytes, got %d", len(header)+3*2*4, len(data))
+		}
+	})
+
+	t.Run("Pixels decode to their unclamped colours", func(t *testing.T) {
+		pixels := []byte(data[len(header):])
+		decode := func(rgbe []byte) Color {
+			if rgbe[3] == 0 {
+				return NewColor(0, 0, 0)
+			}
+			scale := math.Ldexp(1, int(rgbe[3])-128-8)
+			return NewColor(float64(rgbe[0])*scale, float64(rgbe[1])*scale, float64(rgbe[2])*scale)
+		}
+		tests := []struct {
+			x, y     int
+			expected Color
+		}{
+			{0, 0, NewColor(1, 0.5, 0.25)},
+			{1, 0, NewColor(12, 3, 0)},
+			{0, 1, NewColor(0, 0, 0)},
+		}
+		for _, tt := range tests {
+			i := (tt.y*3 + tt.x) * 4
+			assertColorEqual(t, decode(pixels[i:i+4]), tt.expected)
+		}
+		// negative channels are clamped to zero
+		if got := decode(pixels[8:12]); got[R] != 0 || math.Abs(got[G]-0.0001) > 1e-6 {
+			t.Errorf("Expected (0, 0.0001, 0), got %v", got)
+		}
+	})
+}
+
+func TestWritePFM(t *testing.T) {
+	c := NewCanvas(2, 2)
+	c.WritePixel(0, 0, NewColor(4.5, -1, 0.25))
+	c.WritePixel(1, 1, NewColor(0, 100, 1))
+
+	t.Run("Pixels are stored as floats, bottom row first", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := c.WritePFM(&buf); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		header := "PF\n2 2\n-1.0\n"
+		data := buf.Bytes()
+		if string(data[:len(header)]) != header {
+			t.Fatalf("Expected header %q, got %q", header, data[:len(header)])
+		}
+		if len(data) != len(header)+2*2*12 {
+			t.Fatalf("Expected %d bytes, got %d", len(header)+2*2*12, len(data))
+		}
+		pixels := data[len(header):]
+		pixelAt := func(x, y int) Color {
+			i := ((1-y)*2 + x) * 12
+			var rgb [3]float64
+			for k := range rgb {
+				rgb[k] = float64(math.Float32frombits(binary.LittleEndian.Uint32(pixels[i+4*k:])))
+			}
+			return NewColor(rgb[0], rgb[1], rgb[2])
+		}
+		assertColorEqual(t, pixelAt(0, 0), NewColor(4.5, -1, 0.25))
+		assertColorEqual(t, pixelAt(1, 1), NewColor(0, 100, 1))
+		assertColorEqual(t, pixelAt(1, 0), NewColor(0, 0, 0))
+	})
+
+	t.Run("Save picks the format from the extension", func(t *testing.T) {
+		dir := t.TempDir()
+		for _, name := range []string{"image.hdr", "image.pfm"} {
+			filename := filepath.Join(dir, name)
+			if err := c.Save(filename); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			data, _ := os.ReadFile(filename)
+			magic := map[string]string{"image.hdr": "#?RADIANCE", "image.pfm": "PF\n"}[name]
+			if !bytes.HasPrefix(data, []byte(magic)) {
+				t.Errorf("%s: expected the file to start with %q", name, magic)
+			}
+		}
+	})
+}
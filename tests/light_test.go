@@ -0,0 +1,267 @@
+package tests
+
+import (
+	. "github.com/michaelzhao820/raytracer/raytracer"
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestPointLightIntensity(t *testing.T) {
+	w := NewWorld()
+	w.DefaultWorld()
+	light := w.GetLight()
+
+	tests := []struct {
+		point    Tuple
+		expected float64
+	}{
+		{NewPoint(0, 1.0001, 0), 1.0},
+		{NewPoint(-1.0001, 0, 0), 1.0},
+		{NewPoint(0, 0, -1.0001), 1.0},
+		{NewPoint(0, 0, 1.0001), 0.0},
+		{NewPoint(1.0001, 0, 0), 0.0},
+		{NewPoint(0, -1.0001, 0), 0.0},
+		{NewPoint(0, 0, 0), 0.0},
+	}
+	for _, tt := range tests {
+		if got := w.IntensityAt(light, tt.point); got != tt.expected {
+			t.Errorf("Expected intensity %v at %v, got %v", tt.expected, tt.point, got)
+		}
+	}
+}
+
+func TestLightingWithIntensity(t *testing.T) {
+	w := NewWorld()
+	w.DefaultWorld()
+	w.SetLight(&Light{Position: NewPoint(0, 0, -10), Intensity: NewColor(1, 1, 1)})
+	shape := w.GetObjects()[0]
+	m := shape.GetMaterial()
+	m.SetAmbient(0.1)
+	m.SetDiffuse(0.9)
+	m.SetSpecular(0)
+	m.SetColor(1, 1, 1)
+
+	tests := []struct {
+		intensity float64
+		expected  Color
+	}{
+		{1.0, NewColor(1, 1, 1)},
+		{0.5, NewColor(0.55, 0.55, 0.55)},
+		{0.0, NewColor(0.1, 0.1, 0.1)},
+	}
+	for _, tt := range tests {
+		got := Lighting(*m, shape, w.GetLight(), NewPoint(0, 0, -1), NewVector(0, 0, -1), NewVector(0, 0, -1),
+			tt.intensity)
+		assertColorEqual(t, got, tt.expected)
+	}
+}
+
+func TestAreaLight(t *testing.T) {
+	t.Run("Creating an area light", func(t *testing.T) {
+		light := NewAreaLight(NewPoint(0, 0, 0), NewVector(2, 0, 0), 4, NewVector(0, 0, 1), 2, NewColor(1, 1, 1))
+		expected := NewPoint(1, 0, 0.5)
+		if !light.GetPosition().Equals(expected) {
+			t.Errorf("Expected position %v, got %v", expected, light.GetPosition())
+		}
+	})
+
+	t.Run("Finding a single point on an area light", func(t *testing.T) {
+		light := NewAreaLight(NewPoint(0, 0, 0), NewVector(2, 0, 0), 4, NewVector(0, 0, 1), 2, NewColor(1, 1, 1))
+		tests := []struct {
+			u, v     int
+			expected Tuple
+		}{
+			{0, 0, NewPoint(0.25, 0, 0.25)},
+			{1, 0, NewPoint(0.75, 0, 0.25)},
+			{0, 1, NewPoint(0.25, 0, 0.75)},
+			{2, 0, NewPoint(1.25, 0, 0.25)},
+			{3, 1, NewPoint(1.75, 0, 0.75)},
+		}
+		for _, tt := range tests {
+			if got := light.PointOnLight(tt.u, tt.v, nil); !got.Equals(tt.expected) {
+				t.Errorf("Expected point (%d, %d) = %v, got %v", tt.u, tt.v, tt.expected, got)
+			}
+		}
+	})
+
+	t.Run("A jittered point stays inside its cell", func(t *testing.T) {
+		light := NewAreaLight(NewPoint(0, 0, 0), NewVector(2, 0, 0), 4, NewVector(0, 0, 1), 2, NewColor(1, 1, 1))
+		light.SetJitter(true)
+		rng := rand.New(rand.NewPCG(1, 2))
+		for i := 0; i < 100; i++ {
+			p := light.PointOnLight(3, 1, rng)
+			if p[X] < 1.5 || p[X] > 2 || p[Z] < 0.5 || p[Z] > 1 || p[Y] != 0 {
+				t.Fatalf("Expected a point in cell (3, 1), got %v", p)
+			}
+		}
+	})
+
+	t.Run("Jittered soft shadows are the same on every render", func(t *testing.T) {
+		w := NewWorld()
+		w.DefaultWorld()
+		light := NewAreaLight(NewPoint(-1, 2, -10), NewVector(2, 0, 0), 4, NewVector(0, 2, 0), 4, NewColor(1, 1, 1))
+		light.SetJitter(true)
+		w.SetLight(light)
+		floor := NewPlane()
+		m, _ := TranslationMatrix(0, -1, 0)
+		floor.SetTransform(m)
+		w.AddObject(floor)
+
+		render := func(workers int) Canvas {
+			c := NewCamera(20, 20, math.Pi/3)
+			c.SetTransform(ViewTransform(NewPoint(0, 3, 5), NewPoint(0, -1, 0), NewVector(0, 1, 0)))
+			c.SetWorkers(workers)
+			return c.Render(*w)
+		}
+		first, second := render(1), render(4)
+		for y := 0; y < 20; y++ {
+			for x := 0; x < 20; x++ {
+				if !first.PixelAt(x, y).Equals(second.PixelAt(x, y)) {
+					t.Fatalf("Pixel (%d, %d) differs between renders: %v and %v", x, y,
+						first.PixelAt(x, y), second.PixelAt(x, y))
+				}
+			}
+		}
+	})
+
+	t.Run("Step counts below 1 are treated as 1", func(t *testing.T) {
+		light := NewAreaLight(NewPoint(0, 0, 0), NewVector(2, 0, 0), 0, NewVector(0, 0, 1), -3, NewColor(1, 1, 1))
+		if p := light.PointOnLight(0, 0, nil); !p.Equals(NewPoint(1, 0, 0.5)) {
+			t.Errorf("Expected a single sample at the centre, got %v", p)
+		}
+		w := NewWorld()
+		w.DefaultWorld()
+		if got := w.IntensityAt(light, NewPoint(5, 0, 0.5)); got != 1 {
+			t.Errorf("Expected intensity 1, got %v", got)
+		}
+	})
+
+	t.Run("The area light intensity function", func(t *testing.T) {
+		w := NewWorld()
+		w.DefaultWorld()
+		light := NewAreaLight(NewPoint(-0.5, -0.5, -5), NewVector(1, 0, 0), 2, NewVector(0, 1, 0), 2,
+			NewColor(1, 1, 1))
+
+		tests := []struct {
+			point    Tuple
+			expected float64
+		}{
+			{NewPoint(0, 0, 2), 0.0},
+			{NewPoint(1, -1, 2), 0.25},
+			{NewPoint(1.5, 0, 2), 0.5},
+			{NewPoint(1.25, 1.25, 3), 0.75},
+			{NewPoint(0, 0, -2), 1.0},
+		}
+		for _, tt := range tests {
+			if got := w.IntensityAt(light, tt.point); got != tt.expected {
+				t.Errorf("Expected intensity %v at %v, got %v", tt.expected, tt.point, got)
+			}
+		}
+	})
+
+	t.Run("Lighting samples the area light", func(t *testing.T) {
+		light := NewAreaLight(NewPoint(-0.5, -0.5, -5), NewVector(1, 0, 0), 2, NewVector(0, 1, 0), 2,
+			NewColor(1, 1, 1))
+		shape := NewSphere()
+		m := shape.GetMaterial()
+		m.SetAmbient(0.1)
+		m.SetDiffuse(0.9)
+		m.SetSpecular(0)
+		eye := NewPoint(0, 0, -5)
+
+		tests := []struct {
+			point    Tuple
+			expected Color
+		}{
+			{NewPoint(0, 0, -1), NewColor(0.9965, 0.9965, 0.9965)},
+			{NewPoint(0, math.Sqrt2/2, -math.Sqrt2/2), NewColor(0.62318, 0.62318, 0.62318)},
+		}
+		for _, tt := range tests {
+			eyev, _ := eye.Subtract(tt.point)
+			eyev, _ = eyev.Normalize()
+			normalv := NewVector(tt.point[X], tt.point[Y], tt.point[Z])
+			got := Lighting(*m, shape, light, tt.point, eyev, normalv, 1.0)
+			assertColorEqual(t, got, tt.expected)
+		}
+	})
+}
+
+func TestDirectionalLight(t *testing.T) {
+	t.Run("Lighting with a directional light shining at the surface", func(t *testing.T) {
+		m := *DefaultMaterial()
+		light := DirectionalLight{Direction: NewVector(0, 0, 1), Intensity: NewColor(1, 1, 1)}
+		got := Lighting(m, NewSphere(), light, NewPoint(0, 0, 0), NewVector(0, 0, -1), NewVector(0, 0, -1), 1.0)
+		assertColorEqual(t, got, NewColor(1.9, 1.9, 1.9))
+	})
+
+	t.Run("A directional light is blocked however far away the object is", func(t *testing.T) {
+		w := NewWorld()
+		w.DefaultWorld()
+		light := DirectionalLight{Direction: NewVector(0, -1, 0), Intensity: NewColor(1, 1, 1)}
+
+		tests := []struct {
+			point    Tuple
+			expected float64
+		}{
+			{NewPoint(0, -2, 0), 0.0},
+			{NewPoint(0, -1000, 0), 0.0},
+			{NewPoint(0, 2, 0), 1.0},
+			{NewPoint(5, -2, 0), 1.0},
+		}
+		for _, tt := range tests {
+			if got := w.IntensityAt(light, tt.point); got != tt.expected {
+				t.Errorf("Expected intensity %v at %v, got %v", tt.expected, tt.point, got)
+			}
+		}
+	})
+}
+
+func TestSpotLight(t *testing.T) {
+	m := *DefaultMaterial()
+	light := NewSpotLight(NewPoint(0, 0, -10), NewVector(0, 0, 1), math.Pi/18, math.Pi/9, NewColor(1, 1, 1))
+	shade := func(p Tuple) Color {
+		return Lighting(m, NewSphere(), light, p, NewVector(0, 0, -1), NewVector(0, 0, -1), 1.0)
+	}
+
+	t.Run("A point inside the inner cone is fully lit", func(t *testing.T) {
+		assertColorEqual(t, shade(NewPoint(0, 0, 0)), NewColor(1.9, 1.9, 1.9))
+	})
+
+	t.Run("A point outside the outer cone gets no light, not even ambient", func(t *testing.T) {
+		assertColorEqual(t, shade(NewPoint(0, 10, 0)), NewColor(0, 0, 0))
+	})
+
+	t.Run("A point between the cones is partly lit", func(t *testing.T) {
+		inside := shade(NewPoint(0, 10*math.Tan(math.Pi/36), 0))
+		edge := shade(NewPoint(0, 10*math.Tan(math.Pi/12), 0))
+		if !(edge[R] > 0 && edge[R] < inside[R]) {
+			t.Errorf("Expected a colour between black and %v, got %v", inside, edge)
+		}
+	})
+
+	t.Run("A spot light casts shadows", func(t *testing.T) {
+		w := NewWorld()
+		w.DefaultWorld()
+		w.SetLight(light)
+		if got := w.IntensityAt(light, NewPoint(0, 0, 5)); got != 0 {
+			t.Errorf("Expected point behind the spheres to be in shadow, got intensity %v", got)
+		}
+	})
+}
+
+func TestInverseSquareAttenuation(t *testing.T) {
+	m := *DefaultMaterial()
+
+	t.Run("Every term is divided by the square of the distance", func(t *testing.T) {
+		light := Light{Position: NewPoint(0, 0, -2), Intensity: NewColor(4, 4, 4), InverseSquare: true}
+		got := Lighting(m, NewSphere(), light, NewPoint(0, 0, 0), NewVector(0, 0, -1), NewVector(0, 0, -1), 1.0)
+		assertColorEqual(t, got, NewColor(1.9, 1.9, 1.9))
+	})
+
+	t.Run("Ambient light from an inverse-square light falls off too", func(t *testing.T) {
+		far := Light{Position: NewPoint(0, 0, -100), Intensity: NewColor(10000, 10000, 10000), InverseSquare: true}
+		got := Lighting(m, NewSphere(), far, NewPoint(0, 0, 0), NewVector(0, 0, -1), NewVector(0, 0, -1), 0.0)
+		assertColorEqual(t, got, NewColor(0.1, 0.1, 0.1))
+	})
+}
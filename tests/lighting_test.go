@@ -14,8 +14,8 @@ func TestLighting(t *testing.T) {
 		eyev := NewVector(0, 0, -1)
 		normalv := NewVector(0, 0, -1)
 		light := Light{Position: NewPoint(0, 0, -10), Intensity: NewColor(1, 1, 1)}
-		inShadow := false
-		result := Lighting(m, NewSphere(), light, position, eyev, normalv, inShadow)
+		intensity := 1.0
+		result := Lighting(m, NewSphere(), light, position, eyev, normalv, intensity)
 		expected := NewColor(1.9, 1.9, 1.9)
 		assertColorEqual(t, result, expected)
 	})
@@ -24,8 +24,8 @@ func TestLighting(t *testing.T) {
 		eyev := NewVector(0, math.Sqrt2/2, -math.Sqrt2/2)
 		normalv := NewVector(0, 0, -1)
 		light := Light{Position: NewPoint(0, 0, -10), Intensity: NewColor(1, 1, 1)}
-		inShadow := false
-		result := Lighting(m, NewSphere(), light, position, eyev, normalv, inShadow)
+		intensity := 1.0
+		result := Lighting(m, NewSphere(), light, position, eyev, normalv, intensity)
 		expected := NewColor(1.0, 1.0, 1.0)
 		assertColorEqual(t, result, expected)
 	})
@@ -34,10 +34,10 @@ func TestLighting(t *testing.T) {
 		eyev := NewVector(0, 0, -1)
 		normalv := NewVector(0, 0, -1)
 		light := Light{Position: NewPoint(0, 10, -10), Intensity: NewColor(1, 1, 1)}
-		inShadow := false
-		result := Lighting(m, NewSphere(), light, position, eyev, normalv, inShadow)
-		intensity := 0.1 + 0.9*math.Sqrt2/2
-		expected := NewColor(intensity, intensity, intensity)
+		intensity := 1.0
+		result := Lighting(m, NewSphere(), light, position, eyev, normalv, intensity)
+		brightness := 0.1 + 0.9*math.Sqrt2/2
+		expected := NewColor(brightness, brightness, brightness)
 		assertColorEqual(t, result, expected)
 	})
 
@@ -45,10 +45,10 @@ func TestLighting(t *testing.T) {
 		eyev := NewVector(0, -math.Sqrt2/2, -math.Sqrt2/2)
 		normalv := NewVector(0, 0, -1)
 		light := Light{Position: NewPoint(0, 10, -10), Intensity: NewColor(1, 1, 1)}
-		inShadow := false
-		result := Lighting(m, NewSphere(), light, position, eyev, normalv, inShadow)
-		intensity := 0.1 + 0.9*math.Sqrt2/2 + 0.9
-		expected := NewColor(intensity, intensity, intensity)
+		intensity := 1.0
+		result := Lighting(m, NewSphere(), light, position, eyev, normalv, intensity)
+		brightness := 0.1 + 0.9*math.Sqrt2/2 + 0.9
+		expected := NewColor(brightness, brightness, brightness)
 		assertColorEqual(t, result, expected)
 	})
 
@@ -56,8 +56,8 @@ func TestLighting(t *testing.T) {
 		eyev := NewVector(0, 0, -1)
 		normalv := NewVector(0, 0, -1)
 		light := Light{Position: NewPoint(0, 0, 10), Intensity: NewColor(1, 1, 1)}
-		inShadow := false
-		result := Lighting(m, NewSphere(), light, position, eyev, normalv, inShadow)
+		intensity := 1.0
+		result := Lighting(m, NewSphere(), light, position, eyev, normalv, intensity)
 		expected := NewColor(0.1, 0.1, 0.1)
 		assertColorEqual(t, result, expected)
 	})
@@ -66,8 +66,8 @@ func TestLighting(t *testing.T) {
 		eyev := NewVector(0, 0, -1)
 		normalv := NewVector(0, 0, -1)
 		light := Light{Position: NewPoint(0, 0, -10), Intensity: NewColor(1, 1, 1)}
-		inShadow := true
-		result := Lighting(m, NewSphere(), light, position, eyev, normalv, inShadow)
+		intensity := 0.0
+		result := Lighting(m, NewSphere(), light, position, eyev, normalv, intensity)
 		expected := NewColor(0.1, 0.1, 0.1)
 		assertColorEqual(t, result, expected)
 	})
@@ -76,9 +76,9 @@ func TestLighting(t *testing.T) {
 // Helper function for comparing two colors with a small epsilon tolerance
 func assertColorEqual(t *testing.T, got, want Color) {
 	const epsilon = 1e-4
-	if math.Abs(got.Tuple[R]-want.Tuple[R]) > epsilon ||
-		math.Abs(got.Tuple[G]-want.Tuple[G]) > epsilon ||
-		math.Abs(got.Tuple[B]-want.Tuple[B]) > epsilon {
+	if math.Abs(got[R]-want[R]) > epsilon ||
+		math.Abs(got[G]-want[G]) > epsilon ||
+		math.Abs(got[B]-want[B]) > epsilon {
 		t.Errorf("Expected color %v, but got %v", want, got)
 	}
 }
@@ -0,0 +1,111 @@
+package tests
+
+import (
+	. "github.com/michaelzhao820/raytracer/raytracer"
+	"testing"
+)
+
+func TestMat4(t *testing.T) {
+	translation, _ := TranslationMatrix(5, -3, 2)
+	rotation, _ := RotationYMatrix(0.7)
+	scaling, _ := ScalingMatrix(2, 3, 4)
+	transform, _ := translation.MultiplyMatrices(rotation)
+	transform, _ = transform.MultiplyMatrices(scaling)
+
+	t.Run("Converting between Matrix and Mat4 keeps every element", func(t *testing.T) {
+		m, err := transform.Mat4()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !m.Matrix().Equals(transform) {
+			t.Errorf("Expected %v, got %v", transform, m.Matrix())
+		}
+	})
+
+	t.Run("Only a 4x4 matrix converts to a Mat4", func(t *testing.T) {
+		if _, err := NewMatrix(3, 3).Mat4(); err == nil {
+			t.Errorf("Expected an error converting a 3x3 matrix")
+		}
+	})
+
+	t.Run("Mat4 inverse matches Matrix inverse", func(t *testing.T) {
+		m, _ := transform.Mat4()
+		inv, err := m.Inverse()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected, _ := transform.Inverse()
+		if !inv.Matrix().Equals(expected) {
+			t.Errorf("Expected %v, got %v", expected, inv.Matrix())
+		}
+		if !m.MultiplyMatrices(inv).Equals(Identity4()) {
+			t.Errorf("Expected m * inverse(m) to be the identity, got %v", m.MultiplyMatrices(inv))
+		}
+	})
+
+	t.Run("A singular Mat4 cannot be inverted", func(t *testing.T) {
+		m, _ := NewMatrix(4, 4).Mat4()
+		if _, err := m.Inverse(); err == nil {
+			t.Errorf("Expected an error inverting a singular matrix")
+		}
+	})
+
+	t.Run("Multiplying a Mat4 by a Vec4 matches Matrix by Tuple", func(t *testing.T) {
+		m, _ := transform.Mat4()
+		p := NewPoint(1, -2, 3)
+		expected, _ := transform.MultiplyWithTuple(p)
+		got := m.MultiplyWithVec(p.Vec4())
+		if !got.Tuple().Equals(expected) {
+			t.Errorf("Expected %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("Mat4 operations do not allocate", func(t *testing.T) {
+		m, _ := transform.Mat4()
+		v := Point4(1, 2, 3)
+		allocs := testing.AllocsPerRun(100, func() {
+			inv, _ := m.Inverse()
+			v = inv.Transpose().MultiplyWithVec(v)
+		})
+		if allocs != 0 {
+			t.Errorf("Expected no allocations, got %v", allocs)
+		}
+	})
+}
+
+func TestVec4(t *testing.T) {
+	t.Run("Vec4 arithmetic matches Tuple arithmetic", func(t *testing.T) {
+		a, b := NewVector(3, -2, 5), NewVector(-2, 3, 1)
+		va, vb := a.Vec4(), b.Vec4()
+
+		sum, _ := a.Add(b)
+		if !va.Add(vb).Tuple().Equals(sum) {
+			t.Errorf("Expected %v, got %v", sum, va.Add(vb))
+		}
+		diff, _ := a.Subtract(b)
+		if !va.Sub(vb).Tuple().Equals(diff) {
+			t.Errorf("Expected %v, got %v", diff, va.Sub(vb))
+		}
+		dot, _ := Dot(a, b)
+		if va.Dot(vb) != dot {
+			t.Errorf("Expected %v, got %v", dot, va.Dot(vb))
+		}
+		cross, _ := Cross(a, b)
+		if !va.Cross(vb).Tuple().Equals(cross) {
+			t.Errorf("Expected %v, got %v", cross, va.Cross(vb))
+		}
+		norm, _ := a.Normalize()
+		if !va.Normalize().Tuple().Equals(norm) {
+			t.Errorf("Expected %v, got %v", norm, va.Normalize())
+		}
+	})
+
+	t.Run("Reflecting a vector off a slanted surface", func(t *testing.T) {
+		v := Vector4(0, -1, 0)
+		n := Vector4(0.70711, 0.70711, 0)
+		expected := Vector4(1, 0, 0)
+		if got := v.Reflect(n); !got.Equals(expected) {
+			t.Errorf("Expected %v, got %v", expected, got)
+		}
+	})
+}
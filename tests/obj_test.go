@@ -0,0 +1,177 @@
+package tests
+
+import (
+	. "github.com/michaelzhao820/raytracer/raytracer"
+	"strings"
+	"testing"
+)
+
+func TestParseObj(t *testing.T) {
+	t.Run("Ignoring unrecognized lines", func(t *testing.T) {
+		gibberish := `There was a young lady named Bright
+who traveled much faster than light.
+She set out one day
+in a relative way,
+and came back the previous night.`
+
+		obj, err := ParseObj(strings.NewReader(gibberish))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(obj.IgnoredLines) != 5 {
+			t.Errorf("Expected 5 ignored lines, got %v", obj.IgnoredLines)
+		}
+	})
+
+	t.Run("Vertex records", func(t *testing.T) {
+		file := `v -1 1 0
+v -1.0000 0.5000 0.0000
+v 1 0 0
+v 1 1 0`
+
+		obj, err := ParseObj(strings.NewReader(file))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := []Tuple{NewPoint(-1, 1, 0), NewPoint(-1, 0.5, 0), NewPoint(1, 0, 0), NewPoint(1, 1, 0)}
+		if len(obj.Vertices) != len(expected) {
+			t.Fatalf("Expected %d vertices, got %d", len(expected), len(obj.Vertices))
+		}
+		for i, v := range expected {
+			if !obj.Vertices[i].Equals(v) {
+				t.Errorf("Expected vertex %d = %v, got %v", i+1, v, obj.Vertices[i])
+			}
+		}
+	})
+
+	t.Run("Parsing triangle faces", func(t *testing.T) {
+		file := `v -1 1 0
+v -1 0 0
+v 1 0 0
+v 1 1 0
+
+f 1 2 3
+f 1 3 4`
+
+		obj, err := ParseObj(strings.NewReader(file))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		children := obj.DefaultGroup.GetChildren()
+		if len(children) != 2 {
+			t.Fatalf("Expected 2 triangles, got %d", len(children))
+		}
+		p1, p2, p3 := children[1].(*Triangle).GetPoints()
+		if !p1.Equals(obj.Vertices[0]) || !p2.Equals(obj.Vertices[2]) || !p3.Equals(obj.Vertices[3]) {
+			t.Errorf("Second triangle has wrong vertices: %v %v %v", p1, p2, p3)
+		}
+	})
+
+	t.Run("Triangulating polygons", func(t *testing.T) {
+		file := `v -1 1 0
+v -1 0 0
+v 1 0 0
+v 1 1 0
+v 0 2 0
+
+f 1 2 3 4 5`
+
+		obj, err := ParseObj(strings.NewReader(file))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		children := obj.DefaultGroup.GetChildren()
+		if len(children) != 3 {
+			t.Fatalf("Expected 3 triangles, got %d", len(children))
+		}
+		for i, tri := range children {
+			p1, p2, p3 := tri.(*Triangle).GetPoints()
+			if !p1.Equals(obj.Vertices[0]) || !p2.Equals(obj.Vertices[i+1]) || !p3.Equals(obj.Vertices[i+2]) {
+				t.Errorf("Triangle %d has wrong vertices: %v %v %v", i, p1, p2, p3)
+			}
+		}
+	})
+
+	t.Run("Triangles in groups", func(t *testing.T) {
+		file := `v -1 1 0
+v -1 0 0
+v 1 0 0
+v 1 1 0
+g FirstGroup
+f 1 2 3
+g SecondGroup
+f 1 3 4`
+
+		obj, err := ParseObj(strings.NewReader(file))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, name := range []string{"FirstGroup", "SecondGroup"} {
+			g, ok := obj.Groups[name]
+			if !ok {
+				t.Fatalf("Expected group %q", name)
+			}
+			if len(g.GetChildren()) != 1 {
+				t.Errorf("Expected %q to have 1 triangle, got %d", name, len(g.GetChildren()))
+			}
+		}
+
+		g := obj.ToGroup()
+		children := g.GetChildren()
+		if len(children) != 2 || children[0] != obj.Groups["FirstGroup"] || children[1] != obj.Groups["SecondGroup"] {
+			t.Errorf("Expected ToGroup to contain both named groups in order")
+		}
+	})
+
+	t.Run("Faces with normals", func(t *testing.T) {
+		file := `v 0 1 0
+v -1 0 0
+v 1 0 0
+
+vn -1 0 0
+vn 1 0 0
+vn 0 1 0
+
+vt 0 0
+f 1//3 2//1 3//2
+f 1/1/3 2/1/1 3/1/2`
+
+		obj, err := ParseObj(strings.NewReader(file))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		children := obj.DefaultGroup.GetChildren()
+		if len(children) != 2 {
+			t.Fatalf("Expected 2 triangles, got %d", len(children))
+		}
+		for i, child := range children {
+			tri, ok := child.(*SmoothTriangle)
+			if !ok {
+				t.Fatalf("Expected triangle %d to be smooth", i)
+			}
+			n1, n2, n3 := tri.GetNormals()
+			if !n1.Equals(obj.Normals[2]) || !n2.Equals(obj.Normals[0]) || !n3.Equals(obj.Normals[1]) {
+				t.Errorf("Triangle %d has wrong normals: %v %v %v", i, n1, n2, n3)
+			}
+		}
+	})
+
+	t.Run("Errors name the offending line", func(t *testing.T) {
+		tests := []struct {
+			file string
+			want string
+		}{
+			{"v 1 2\n", "obj line 1"},
+			{"v 1 2 3\nv 1 x 3\n", "obj line 2"},
+			{"v 1 2 3\n\nf 1 2 3\n", "obj line 3"},
+			{"v 1 2 3\nv 1 2 3\nv 1 2 3\nf 1 2\n", "obj line 4"},
+		}
+
+		for _, tt := range tests {
+			_, err := ParseObj(strings.NewReader(tt.file))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		}
+	})
+}
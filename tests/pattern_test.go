@@ -11,46 +11,46 @@ func TestStripePattern(t *testing.T) {
 	pattern := NewStripePattern(white, black)
 
 	t.Run("stripe pattern is constant in y", func(t *testing.T) {
-		if !pattern.PatternAt(NewPoint(0, 0, 0)).Equals(white) {
+		if !pattern.PatternAt(Point4(0, 0, 0)).Equals(white) {
 			t.Error("Expected white at (0, 0, 0)")
 		}
-		if !pattern.PatternAt(NewPoint(0, 1, 0)).Equals(white) {
+		if !pattern.PatternAt(Point4(0, 1, 0)).Equals(white) {
 			t.Error("Expected white at (0, 1, 0)")
 		}
-		if !pattern.PatternAt(NewPoint(0, 2, 0)).Equals(white) {
+		if !pattern.PatternAt(Point4(0, 2, 0)).Equals(white) {
 			t.Error("Expected white at (0, 2, 0)")
 		}
 	})
 
 	t.Run("stripe pattern is constant in z", func(t *testing.T) {
-		if !pattern.PatternAt(NewPoint(0, 0, 0)).Equals(white) {
+		if !pattern.PatternAt(Point4(0, 0, 0)).Equals(white) {
 			t.Error("Expected white at (0, 0, 0)")
 		}
-		if !pattern.PatternAt(NewPoint(0, 0, 1)).Equals(white) {
+		if !pattern.PatternAt(Point4(0, 0, 1)).Equals(white) {
 			t.Error("Expected white at (0, 0, 1)")
 		}
-		if !pattern.PatternAt(NewPoint(0, 0, 2)).Equals(white) {
+		if !pattern.PatternAt(Point4(0, 0, 2)).Equals(white) {
 			t.Error("Expected white at (0, 0, 2)")
 		}
 	})
 
 	t.Run("stripe pattern alternates in x", func(t *testing.T) {
-		if !pattern.PatternAt(NewPoint(0.0, 0, 0)).Equals(white) {
+		if !pattern.PatternAt(Point4(0.0, 0, 0)).Equals(white) {
 			t.Error("Expected white at (0.0, 0, 0)")
 		}
-		if !pattern.PatternAt(NewPoint(0.9, 0, 0)).Equals(white) {
+		if !pattern.PatternAt(Point4(0.9, 0, 0)).Equals(white) {
 			t.Error("Expected white at (0.9, 0, 0)")
 		}
-		if !pattern.PatternAt(NewPoint(1.0, 0, 0)).Equals(black) {
+		if !pattern.PatternAt(Point4(1.0, 0, 0)).Equals(black) {
 			t.Error("Expected black at (1.0, 0, 0)")
 		}
-		if !pattern.PatternAt(NewPoint(-0.1, 0, 0)).Equals(black) {
+		if !pattern.PatternAt(Point4(-0.1, 0, 0)).Equals(black) {
 			t.Error("Expected black at (-0.1, 0, 0)")
 		}
-		if !pattern.PatternAt(NewPoint(-1.0, 0, 0)).Equals(black) {
+		if !pattern.PatternAt(Point4(-1.0, 0, 0)).Equals(black) {
 			t.Error("Expected black at (-1.0, 0, 0)")
 		}
-		if !pattern.PatternAt(NewPoint(-1.1, 0, 0)).Equals(white) {
+		if !pattern.PatternAt(Point4(-1.1, 0, 0)).Equals(white) {
 			t.Error("Expected white at (-1.1, 0, 0)")
 		}
 	})
@@ -79,8 +79,8 @@ func TestLightingWithPatternApplied(t *testing.T) {
 	}
 
 	// When: lighting at two points
-	c1 := Lighting(*m, NewSphere(), light, NewPoint(0.9, 0, 0), eyev, normalv, false)
-	c2 := Lighting(*m, NewSphere(), light, NewPoint(1.1, 0, 0), eyev, normalv, false)
+	c1 := Lighting(*m, NewSphere(), light, NewPoint(0.9, 0, 0), eyev, normalv, 1.0)
+	c2 := Lighting(*m, NewSphere(), light, NewPoint(1.1, 0, 0), eyev, normalv, 1.0)
 
 	if !c1.Equals(white) {
 		t.Errorf("Expected c1 to be white, got %v", c1)
@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"bytes"
+	. "github.com/michaelzhao820/raytracer/raytracer"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"path/filepath"
+	"testing"
+)
+
+func TestCanvasImage(t *testing.T) {
+	t.Run("A canvas is a draw.Image with the canvas's bounds", func(t *testing.T) {
+		c := NewCanvas(10, 20)
+		var img draw.Image = &c
+		expected := image.Rect(0, 0, 10, 20)
+		if img.Bounds() != expected {
+			t.Errorf("Expected bounds %v, got %v", expected, img.Bounds())
+		}
+	})
+
+	t.Run("At encodes linear colours with the sRGB transfer function", func(t *testing.T) {
+		c := NewCanvas(4, 1)
+		c.WritePixel(0, 0, NewColor(0, 1, 2))
+		c.WritePixel(1, 0, NewColor(0.5, 0.5, 0.5))
+		c.WritePixel(2, 0, NewColor(0.002, 0.2140, -1))
+
+		tests := []struct {
+			x        int
+			expected color.RGBA
+		}{
+			{0, color.RGBA{0, 255, 255, 255}},
+			{1, color.RGBA{188, 188, 188, 255}},
+			{2, color.RGBA{6, 127, 0, 255}},
+		}
+		for _, tt := range tests {
+			got := color.RGBAModel.Convert(c.At(tt.x, 0))
+			if got != tt.expected {
+				t.Errorf("Pixel %d: expected %v, got %v", tt.x, tt.expected, got)
+			}
+		}
+		if got := c.At(4, 0); got != (color.RGBA64{}) {
+			t.Errorf("Expected transparent black off the canvas, got %v", got)
+		}
+	})
+
+	t.Run("Set decodes sRGB colours back to linear", func(t *testing.T) {
+		c := NewCanvas(2, 1)
+		c.Set(0, 0, color.RGBA{255, 188, 0, 255})
+		c.Set(1, 0, color.RGBA{128, 128, 128, 128})
+		assertColorEqual(t, c.PixelAt(0, 0), NewColor(1, 0.5029, 0))
+		// half-transparent white is stored as if composited over black
+		assertColorEqual(t, c.PixelAt(1, 0), NewColor(0.2158, 0.2158, 0.2158))
+	})
+
+	t.Run("Drawing onto a canvas with the image/draw package", func(t *testing.T) {
+		c := NewCanvas(4, 4)
+		red := image.NewUniform(color.RGBA{255, 0, 0, 255})
+		draw.Draw(&c, image.Rect(1, 1, 3, 3), red, image.Point{}, draw.Src)
+		assertColorEqual(t, c.PixelAt(0, 0), NewColor(0, 0, 0))
+		assertColorEqual(t, c.PixelAt(1, 1), NewColor(1, 0, 0))
+		assertColorEqual(t, c.PixelAt(2, 2), NewColor(1, 0, 0))
+		assertColorEqual(t, c.PixelAt(3, 3), NewColor(0, 0, 0))
+	})
+
+	t.Run("Converting an image with an offset origin to a canvas", func(t *testing.T) {
+		img := image.NewGray(image.Rect(5, 5, 7, 8))
+		img.SetGray(6, 7, color.Gray{255})
+		c := CanvasFromImage(img)
+		if c.GetWidth() != 2 || c.Height != 3 {
+			t.Fatalf("Expected a 2x3 canvas, got %dx%d", c.GetWidth(), c.Height)
+		}
+		assertColorEqual(t, c.PixelAt(1, 2), NewColor(1, 1, 1))
+		assertColorEqual(t, c.PixelAt(0, 0), NewColor(0, 0, 0))
+	})
+}
+
+func TestWritePNG(t *testing.T) {
+	c := NewCanvas(3, 2)
+	c.WritePixel(0, 0, NewColor(1, 0, 0))
+	c.WritePixel(1, 0, NewColor(0.5, 0.5, 0.5))
+	c.WritePixel(2, 1, NewColor(0, 0, 1))
+
+	t.Run("A PNG decodes to the canvas's sRGB pixels", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := c.WritePNG(&buf); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		img, err := png.Decode(&buf)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for y := 0; y < 2; y++ {
+			for x := 0; x < 3; x++ {
+				want := color.RGBAModel.Convert(c.At(x, y))
+				got := color.RGBAModel.Convert(img.At(x, y))
+				if got != want {
+					t.Errorf("Pixel (%d, %d): expected %v, got %v", x, y, want, got)
+				}
+			}
+		}
+	})
+
+	t.Run("Save writes a PNG for a .png file name", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "image.PNG")
+		if err := c.Save(filename); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := c.Save(filepath.Join(t.TempDir(), "image.gif")); err == nil {
+			t.Errorf("Expected an error for an unsupported format")
+		}
+	})
+}
@@ -0,0 +1,205 @@
+package tests
+
+import (
+	"bytes"
+	. "github.com/michaelzhao820/raytracer/raytracer"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWritePPM(t *testing.T) {
+	t.Run("Constructing the PPM header and pixel data", func(t *testing.T) {
+		c := NewCanvas(5, 3)
+		c.WritePixel(0, 0, NewColor(1.5, 0, 0))
+		c.WritePixel(2, 1, NewColor(0, 0.5, 0))
+		c.WritePixel(4, 2, NewColor(-0.5, 0, 1))
+
+		var buf bytes.Buffer
+		if err := c.WritePPM(&buf); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := "P3\n5 3\n255\n" +
+			"255 0 0 0 0 0 0 0 0 0 0 0 0 0 0\n" +
+			"0 0 0 0 0 0 0 127 0 0 0 0 0 0 0\n" +
+			"0 0 0 0 0 0 0 0 0 0 0 0 0 0 255\n"
+		if buf.String() != expected {
+			t.Errorf("Expected:\n%s\ngot:\n%s", expected, buf.String())
+		}
+	})
+
+	t.Run("Splitting long lines in PPM files", func(t *testing.T) {
+		c := NewCanvas(10, 2)
+		for y := 0; y < 2; y++ {
+			for x := 0; x < 10; x++ {
+				c.WritePixel(x, y, NewColor(1, 0.8, 0.6))
+			}
+		}
+
+		var buf bytes.Buffer
+		c.WritePPM(&buf)
+		lines := strings.Split(buf.String(), "\n")
+		expected := []string{
+			"255 204 153 255 204 153 255 204 153 255 204 153 255 204 153 255 204",
+			"153 255 204 153 255 204 153 255 204 153 255 204 153",
+		}
+		if lines[3] != expected[0] || lines[4] != expected[1] {
+			t.Errorf("Expected lines %q, got %q", expected, lines[3:5])
+		}
+		for _, line := range lines {
+			if len(line) > 70 {
+				t.Errorf("Line longer than 70 characters: %q", line)
+			}
+		}
+		if !strings.HasSuffix(buf.String(), "\n") {
+			t.Errorf("Expected PPM to end with a newline")
+		}
+	})
+
+	t.Run("A binary PPM stores each pixel in three bytes", func(t *testing.T) {
+		c := NewCanvas(2, 1)
+		c.WritePixel(0, 0, NewColor(1, 0.5, 0))
+		c.WritePixel(1, 0, NewColor(0, 0, 2))
+
+		var buf bytes.Buffer
+		if err := c.WritePPMBinary(&buf); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := "P6\n2 1\n255\n\xff\x7f\x00\x00\x00\xff"
+		if buf.String() != expected {
+			t.Errorf("Expected %q, got %q", expected, buf.String())
+		}
+	})
+}
+
+func TestCanvasFromPPM(t *testing.T) {
+	t.Run("Reading an ASCII PPM with comments and a custom maximum value", func(t *testing.T) {
+		data := "P3\n# made by hand\n2 2 # width and height\n100\n" +
+			"100 100 100  50 50 50\n" +
+			"# second row\n" +
+			"75 50 25  0 0 0\n"
+		c, err := CanvasFromPPM(strings.NewReader(data))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if c.GetWidth() != 2 || c.Height != 2 {
+			t.Fatalf("Expected a 2x2 canvas, got %dx%d", c.GetWidth(), c.Height)
+		}
+		tests := []struct {
+			x, y     int
+			expected Color
+		}{
+			{0, 0, NewColor(1, 1, 1)},
+			{1, 0, NewColor(0.5, 0.5, 0.5)},
+			{0, 1, NewColor(0.75, 0.5, 0.25)},
+			{1, 1, NewColor(0, 0, 0)},
+		}
+		for _, tt := range tests {
+			if got := c.PixelAt(tt.x, tt.y); !got.Equals(tt.expected) {
+				t.Errorf("Pixel (%d, %d): expected %v, got %v", tt.x, tt.y, tt.expected, got)
+			}
+		}
+	})
+
+	t.Run("Reading a binary PPM with two bytes per sample", func(t *testing.T) {
+		data := "P6 1 1 65535\n\xff\xff\x80\x00\x00\x00"
+		c, err := CanvasFromPPM(strings.NewReader(data))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := NewColor(1, 32768.0/65535, 0)
+		if !c.PixelAt(0, 0).Equals(expected) {
+			t.Errorf("Expected %v, got %v", expected, c.PixelAt(0, 0))
+		}
+	})
+
+	t.Run("A saved canvas reads back unchanged", func(t *testing.T) {
+		c := NewCanvas(3, 2)
+		c.WritePixel(0, 0, NewColor(1, 0, 0))
+		c.WritePixel(1, 1, NewColor(0, 1, 1))
+		c.WritePixel(2, 1, NewColor(0.2, 0.4, 0.6))
+
+		for _, name := range []string{"ascii", "binary"} {
+			var buf bytes.Buffer
+			if name == "ascii" {
+				c.WritePPM(&buf)
+			} else {
+				c.WritePPMBinary(&buf)
+			}
+			read, err := CanvasFromPPM(&buf)
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", name, err)
+			}
+			for y := 0; y < 2; y++ {
+				for x := 0; x < 3; x++ {
+					want, got := c.PixelAt(x, y), read.PixelAt(x, y)
+					for i := R; i <= B; i++ {
+						if got[i] > want[i] || want[i]-got[i] > 1.0/255 {
+							t.Errorf("%s: pixel (%d, %d): expected %v, got %v", name, x, y, want, got)
+						}
+					}
+				}
+			}
+		}
+	})
+
+	t.Run("Save writes a binary PPM that can be read back", func(t *testing.T) {
+		c := NewCanvas(4, 4)
+		c.WritePixel(3, 3, NewColor(1, 1, 1))
+		filename := filepath.Join(t.TempDir(), "image.ppm")
+		if err := c.Save(filename); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		read, err := CanvasFromPPMFile(filename)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !read.PixelAt(3, 3).Equals(NewColor(1, 1, 1)) {
+			t.Errorf("Expected a white pixel, got %v", read.PixelAt(3, 3))
+		}
+	})
+
+	t.Run("Save encodes a PPM as sRGB, like a PNG", func(t *testing.T) {
+		c := NewCanvas(1, 1)
+		c.WritePixel(0, 0, NewColor(0.2, 0.5, 0.05))
+		filename := filepath.Join(t.TempDir(), "image.ppm")
+		if err := c.Save(filename); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		read, err := CanvasFromPPMFile(filename)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		want, got := LinearToSRGB(c.PixelAt(0, 0)), read.PixelAt(0, 0)
+		for i := R; i <= B; i++ {
+			if math.Abs(got[i]-want[i]) > 1.0/255 {
+				t.Errorf("Expected %v, got %v", want, got)
+				break
+			}
+		}
+	})
+
+	t.Run("Malformed files are rejected", func(t *testing.T) {
+		tests := []struct {
+			name, data, message string
+		}{
+			{"wrong magic number", "P5\n1 1\n255\n\x00", "unsupported format"},
+			{"missing header", "P3\n1 1\n", "missing maximum value"},
+			{"zero width", "P3\n0 1\n255\n", "invalid width"},
+			{"non-numeric height", "P3\n1 x\n255\n", "invalid height"},
+			{"maximum value too large", "P3\n1 1\n70000\n0 0 0", "invalid maximum value"},
+			{"sample above maximum", "P3\n1 1\n10\n0 11 0", "sample 11 is outside 0-10"},
+			{"truncated ASCII data", "P3\n2 1\n255\n0 0 0 0", "pixel (1, 0): unexpected end of data"},
+			{"truncated binary data", "P6\n1 1\n255\n\x00\x00", "pixel (0, 0): unexpected end of data"},
+			{"huge header with no data", "P6\n65536 65536\n255\n", "pixel (0, 0): unexpected end of data"},
+			{"huge header with truncated data", "P3\n65536 65536\n255\n1 2 3 4 5 6", "pixel (2, 0): unexpected end of data"},
+		}
+		for _, tt := range tests {
+			_, err := CanvasFromPPM(strings.NewReader(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.message, err)
+			}
+		}
+	})
+}
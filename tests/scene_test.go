@@ -0,0 +1,361 @@
+package tests
+
+import (
+	. "github.com/michaelzhao820/raytracer/raytracer"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const bookScene = `
+# a scene in the book's format
+- add: camera
+  width: 100
+  height: 50
+  field-of-view: 0.785
+  from: [ 0, 1.5, -5 ]
+  to: [ 0, 1, 0 ]
+  up: [ 0, 1, 0 ]
+
+- add: light
+  at: [ -10, 10, -10 ]
+  intensity: [ 1, 1, 1 ]
+
+- define: white-material
+  value:
+    color: [ 1, 1, 1 ]
+    diffuse: 0.7
+    ambient: 0.1
+    specular: 0.0
+    reflective: 0.1
+
+- define: blue-material
+  extend: white-material
+  value:
+    color: [ 0.537, 0.831, 0.914 ]
+
+- define: standard-transform
+  value:
+  - [ translate, 1, -1, 1 ]
+  - [ scale, 0.5, 0.5, 0.5 ]
+
+- add: cube
+  material: blue-material
+  transform:
+    - standard-transform
+    - [ translate, 4, 0, 0 ]
+
+- add: plane
+  material:
+    pattern:
+      type: checkers
+      colors:
+        - [ 0.35, 0.35, 0.35 ]
+        - [ 0.65, 0.65, 0.65 ]
+      transform:
+        - [ scale, 2, 2, 2 ]
+    specular: 0
+
+- add: group
+  children:
+    - add: cylinder
+      min: 0
+      max: 1
+      closed: true
+    - { add: sphere, transform: [ [ rotate-y, 1.5707963 ] ] }
+`
+
+func TestLoadScene(t *testing.T) {
+	scene, err := LoadScene(strings.NewReader(bookScene))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	objects := scene.World.GetObjects()
+
+	t.Run("The camera is built from its size and view", func(t *testing.T) {
+		if scene.Camera == nil {
+			t.Fatalf("Expected a camera")
+		}
+		expected := ViewTransform(NewPoint(0, 1.5, -5), NewPoint(0, 1, 0), NewVector(0, 1, 0))
+		if !scene.Camera.GetTransform().Equals(expected) {
+			t.Errorf("Expected view transform %v, got %v", expected, scene.Camera.GetTransform())
+		}
+		if scene.Camera.GetHSize() != 100 || scene.Camera.GetVSize() != 50 {
+			t.Errorf("Expected a 100x50 camera, got %vx%v", scene.Camera.GetHSize(), scene.Camera.GetVSize())
+		}
+	})
+
+	t.Run("Lights and objects are added in order", func(t *testing.T) {
+		l, ok := scene.World.GetLight().(*Light)
+		if !ok || !l.Position.Equals(NewPoint(-10, 10, -10)) || !l.Intensity.Equals(NewColor(1, 1, 1)) {
+			t.Errorf("Expected a white point light at (-10, 10, -10), got %v", scene.World.GetLight())
+		}
+		if len(objects) != 3 {
+			t.Fatalf("Expected 3 objects, got %d", len(objects))
+		}
+		if _, ok := objects[0].(*Cube); !ok {
+			t.Errorf("Expected a cube, got %T", objects[0])
+		}
+		if _, ok := objects[1].(*Plane); !ok {
+			t.Errorf("Expected a plane, got %T", objects[1])
+		}
+	})
+
+	t.Run("A material extends a defined material", func(t *testing.T) {
+		m := objects[0].GetMaterial()
+		expected := DefaultMaterial()
+		expected.SetColor(0.537, 0.831, 0.914)
+		expected.SetDiffuse(0.7)
+		expected.SetAmbient(0.1)
+		expected.SetSpecular(0)
+		expected.SetReflective(0.1)
+		if !reflect.DeepEqual(m, expected) {
+			t.Errorf("Expected material %v, got %v", *expected, *m)
+		}
+	})
+
+	t.Run("Transforms apply in order, expanding defined ones", func(t *testing.T) {
+		translate, _ := TranslationMatrix(1, -1, 1)
+		scale, _ := ScalingMatrix(0.5, 0.5, 0.5)
+		move, _ := TranslationMatrix(4, 0, 0)
+		expected, _ := scale.MultiplyMatrices(translate)
+		expected, _ = move.MultiplyMatrices(expected)
+		if !objects[0].GetTransformMatrix().Equals(expected) {
+			t.Errorf("Expected transform %v, got %v", expected, objects[0].GetTransformMatrix())
+		}
+	})
+
+	t.Run("Patterns are built with their colors and transform", func(t *testing.T) {
+		p := objects[1].GetMaterial().Pattern
+		if _, ok := p.(*Checker3DPattern); !ok {
+			t.Fatalf("Expected a checkers pattern, got %T", p)
+		}
+		if got := p.PatternAtObject(objects[1], Point4(1, 0, 0)); !got.Equals(NewColor(0.35, 0.35, 0.35)) {
+			t.Errorf("Expected the pattern to be scaled, got %v", got)
+		}
+		if got := p.PatternAtObject(objects[1], Point4(2.5, 0, 0)); !got.Equals(NewColor(0.65, 0.65, 0.65)) {
+			t.Errorf("Expected the second color, got %v", got)
+		}
+	})
+
+	t.Run("Groups hold their children", func(t *testing.T) {
+		g, ok := objects[2].(*Group)
+		if !ok {
+			t.Fatalf("Expected a group, got %T", objects[2])
+		}
+		children := g.GetChildren()
+		if len(children) != 2 {
+			t.Fatalf("Expected 2 children, got %d", len(children))
+		}
+		cy, ok := children[0].(*Cylinder)
+		if !ok || cy.Minimum != 0 || cy.Maximum != 1 || !cy.Closed {
+			t.Errorf("Expected a closed cylinder from 0 to 1, got %v", children[0])
+		}
+		rotation, _ := RotationYMatrix(1.5707963)
+		if !children[1].GetTransformMatrix().Equals(rotation) {
+			t.Errorf("Expected a rotated sphere, got %v", children[1].GetTransformMatrix())
+		}
+	})
+}
+
+func TestLoadSceneShapes(t *testing.T) {
+	t.Run("Adding a defined shape with overrides", func(t *testing.T) {
+		scene, err := LoadScene(strings.NewReader(`
+- define: pillar
+  value:
+    add: cylinder
+    min: 0
+    max: 3
+    material:
+      color: [1, 0, 0]
+- add: pillar
+  max: 5
+`))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		cy, ok := scene.World.GetObjects()[0].(*Cylinder)
+		if !ok || cy.Minimum != 0 || cy.Maximum != 5 {
+			t.Fatalf("Expected a cylinder from 0 to 5, got %v", scene.World.GetObjects()[0])
+		}
+		if scene.Camera != nil {
+			t.Errorf("Expected no camera")
+		}
+	})
+
+	t.Run("CSG, triangles and area lights", func(t *testing.T) {
+		scene, err := LoadScene(strings.NewReader(`
+- add: light
+  corner: [-1, 2, 4]
+  uvec: [2, 0, 0]
+  vvec: [0, 2, 0]
+  usteps: 4
+  vsteps: 2
+  jitter: true
+  intensity: [1.5, 1.5, 1.5]
+- add: csg
+  operation: difference
+  left:
+    add: cube
+  right:
+    add: sphere
+    transform: [[scale, 1.3, 1.3, 1.3]]
+- add: triangle
+  p1: [0, 1, 0]
+  p2: [-1, 0, 0]
+  p3: [1, 0, 0]
+`))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		a, ok := scene.World.GetLight().(*AreaLight)
+		if !ok || !a.GetPosition().Equals(NewPoint(0, 3, 4)) {
+			t.Errorf("Expected an area light centred on (0, 3, 4), got %v", scene.World.GetLight())
+		}
+		c, ok := scene.World.GetObjects()[0].(*CSG)
+		if !ok || c.GetOperation() != CSGDifference {
+			t.Fatalf("Expected a CSG difference, got %v", scene.World.GetObjects()[0])
+		}
+		if _, ok := c.GetLeft().(*Cube); !ok {
+			t.Errorf("Expected a cube on the left, got %T", c.GetLeft())
+		}
+		tr, ok := scene.World.GetObjects()[1].(*Triangle)
+		if !ok {
+			t.Fatalf("Expected a triangle, got %T", scene.World.GetObjects()[1])
+		}
+		if p1, _, _ := tr.GetPoints(); !p1.Equals(NewPoint(0, 1, 0)) {
+			t.Errorf("Expected p1 (0, 1, 0), got %v", p1)
+		}
+	})
+
+	t.Run("OBJ files are found next to the scene file", func(t *testing.T) {
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, "triangle.obj"), []byte("v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 3\n"), 0o644)
+		filename := filepath.Join(dir, "scene.yml")
+		os.WriteFile(filename, []byte("- add: obj\n  file: triangle.obj\n"), 0o644)
+
+		scene, err := LoadSceneFile(filename)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		g, ok := scene.World.GetObjects()[0].(*Group)
+		if !ok || len(g.GetChildren()) != 1 {
+			t.Errorf("Expected a group with the OBJ's default group, got %v", scene.World.GetObjects()[0])
+		}
+	})
+
+	t.Run("A group's material applies to the shapes inside it", func(t *testing.T) {
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, "triangle.obj"), []byte("v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 3\n"), 0o644)
+		filename := filepath.Join(dir, "scene.yml")
+		os.WriteFile(filename, []byte(`
+- add: group
+  material:
+    color: [1, 0, 0]
+  children:
+    - add: sphere
+    - add: cube
+      material:
+        color: [0, 0, 1]
+    - add: csg
+      operation: union
+      left:
+        add: sphere
+      right:
+        add: obj
+        file: triangle.obj
+`), 0o644)
+
+		scene, err := LoadSceneFile(filename)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		red := DefaultMaterial()
+		red.SetColor(1, 0, 0)
+		blue := DefaultMaterial()
+		blue.SetColor(0, 0, 1)
+
+		children := scene.World.GetObjects()[0].(*Group).GetChildren()
+		c := children[2].(*CSG)
+		triangle := c.GetRight().(*Group).GetChildren()[0].(*Group).GetChildren()[0]
+		for _, tc := range []struct {
+			name     string
+			shape    Shape
+			expected *Material
+		}{
+			{"sphere", children[0], red},
+			{"cube with its own material", children[1], blue},
+			{"csg operand", c.GetLeft(), red},
+			{"obj triangle", triangle, red},
+		} {
+			if !reflect.DeepEqual(tc.shape.GetMaterial(), tc.expected) {
+				t.Errorf("Expected %s material %v, got %v", tc.name, *tc.expected, *tc.shape.GetMaterial())
+			}
+		}
+	})
+
+	t.Run("A loaded scene renders", func(t *testing.T) {
+		scene, err := LoadScene(strings.NewReader(`
+- add: camera
+  width: 11
+  height: 11
+  field-of-view: 1.5707963
+  from: [0, 0, -5]
+  to: [0, 0, 0]
+  up: [0, 1, 0]
+- add: light
+  at: [-10, 10, -10]
+  intensity: [1, 1, 1]
+- add: sphere
+  material:
+    color: [0.8, 1.0, 0.6]
+    diffuse: 0.7
+    specular: 0.2
+- add: sphere
+  transform:
+    - [scale, 0.5, 0.5, 0.5]
+`))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		image := scene.Camera.Render(*scene.World)
+		assertColorEqual(t, image.PixelAt(5, 5), NewColor(0.38066, 0.47583, 0.2855))
+		if math.IsNaN(image.PixelAt(0, 0)[R]) {
+			t.Errorf("Expected a colour in the corner")
+		}
+	})
+}
+
+func TestLoadSceneErrors(t *testing.T) {
+	tests := []struct {
+		name, scene, message string
+	}{
+		{"not a list", "add: sphere", "scene line 1: expected a list of add and define entries"},
+		{"unknown shape", "- add: teapot", "scene line 1: cannot add \"teapot\""},
+		{"unknown key", "- add: sphere\n  colour: [1, 0, 0]", "scene line 2: unknown key \"colour\""},
+		{"unknown material key", "- add: sphere\n  material:\n    shiny: 4", "scene line 3: unknown material key \"shiny\""},
+		{"bad number", "- add: cylinder\n  min: low", "scene line 2: expected a number, got \"low\""},
+		{"short tuple", "- add: light\n  at: [1, 2]\n  intensity: [1, 1, 1]", "scene line 2: expected a list of 3 numbers"},
+		{"missing camera key", "- add: camera\n  width: 10\n  height: 10\n  field-of-view: 1\n  from: [0, 0, 0]\n  to: [0, 0, 1]", "scene line 1: camera needs up"},
+		{"bad camera size", "- add: camera\n  width: 0", "scene line 2: width must be a whole number of at least 1"},
+		{"undefined name", "- add: sphere\n  material: gold", "scene line 2: \"gold\" is not defined"},
+		{"bad transformation", "- add: sphere\n  transform:\n    - [translate, 1, 2]", "scene line 3: translate needs 3 numbers, got 2"},
+		{"unknown transformation", "- add: sphere\n  transform:\n    - [spin, 1]", "scene line 3: unknown transformation \"spin\""},
+		{"singular transform", "- add: sphere\n  transform: [[scale, 0, 1, 1]]", "scene line 2: transform cannot be inverted"},
+		{"unknown pattern", "- add: plane\n  material:\n    pattern:\n      type: dots\n      colors: [[1, 1, 1], [0, 0, 0]]", "scene line 4: unknown pattern type \"dots\""},
+		{"extend a list", "- define: t\n  value: [[scale, 1, 1, 1]]\n- define: u\n  extend: t\n  value:\n    color: [1, 1, 1]", "scene line 4: only mappings can extend each other"},
+		{"two cameras", "- add: camera\n  width: 1\n  height: 1\n  field-of-view: 1\n  from: [0, 0, 0]\n  to: [0, 0, 1]\n  up: [0, 1, 0]\n- add: camera", "scene line 8: the scene already has a camera"},
+		{"bad indentation", "- add: sphere\n    material: x\n  transform: []", "scene line 2: unexpected indentation"},
+		{"unclosed list", "- add: sphere\n  transform: [[scale, 1, 1, 1]", "scene line 2: unclosed [ or {"},
+		{"self-referencing shape", "- define: s\n  value:\n    add: s\n- add: s", "scene line 3: \"s\" is not a shape"},
+	}
+	for _, tt := range tests {
+		_, err := LoadScene(strings.NewReader(tt.scene))
+		if err == nil || !strings.HasPrefix(err.Error(), tt.message) {
+			t.Errorf("%s: expected an error starting %q, got %v", tt.name, tt.message, err)
+		}
+	}
+}
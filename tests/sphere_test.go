@@ -86,3 +86,79 @@ func TestSphereNormals(t *testing.T) {
 		}
 	})
 }
+
+func TestSingularTransforms(t *testing.T) {
+	singular, _ := ScalingMatrix(1, 0, 1)
+
+	t.Run("A shape rejects a transform that cannot be inverted", func(t *testing.T) {
+		s := NewSphere()
+		if err := s.SetTransform(singular); err == nil {
+			t.Errorf("Expected an error for a singular transform")
+		}
+		if !s.GetTransformMatrix().Equals(IdentityMatrix()) {
+			t.Errorf("Expected the transform to be unchanged, got %v", s.GetTransformMatrix())
+		}
+	})
+
+	t.Run("A shape accepts a small uniform scale", func(t *testing.T) {
+		s := NewSphere()
+		m, _ := ScalingMatrix(1e-4, 1e-4, 1e-4)
+		if err := s.SetTransform(m); err != nil {
+			t.Errorf("Unexpected error for a scale of 1e-4: %v", err)
+		}
+	})
+
+	t.Run("A shape rejects a transform whose rows depend on each other", func(t *testing.T) {
+		// the third row is the first plus 0.3 times the second, which rounding
+		// leaves with a determinant of about 1e-17 rather than exactly zero
+		rows := [4][4]float64{
+			{0.1, 0.2, 0.3, 0},
+			{0.7, 0.5, 0.3, 0},
+			{0.31, 0.35, 0.39, 0},
+			{0, 0, 0, 1},
+		}
+		m := NewMatrix(4, 4)
+		for i, row := range rows {
+			for j, v := range row {
+				m, _ = m.Set(i, j, v)
+			}
+		}
+		s := NewSphere()
+		if err := s.SetTransform(m); err == nil {
+			t.Errorf("Expected an error for a rank-deficient transform")
+		}
+		if _, err := m.Inverse(); err == nil {
+			t.Errorf("Expected Matrix.Inverse to reject a rank-deficient matrix")
+		}
+	})
+
+	t.Run("A shape caches the inverse of its transform", func(t *testing.T) {
+		s := NewSphere()
+		m, _ := ScalingMatrix(0.01, 0.01, 0.01)
+		if err := s.SetTransform(m); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		scaling, _ := ScalingMatrix(100, 100, 100)
+		expected, _ := scaling.Mat4()
+		if !s.GetInverseMatrix().Equals(expected) {
+			t.Errorf("Expected inverse %v, got %v", expected, s.GetInverseMatrix())
+		}
+		if !s.GetInverseTransposeMatrix().Equals(expected) {
+			t.Errorf("Expected inverse-transpose %v, got %v", expected, s.GetInverseTransposeMatrix())
+		}
+	})
+
+	t.Run("A pattern rejects a transform that cannot be inverted", func(t *testing.T) {
+		p := NewStripePattern(NewColor(1, 1, 1), NewColor(0, 0, 0))
+		if err := p.SetTransform(singular); err == nil {
+			t.Errorf("Expected an error for a singular transform")
+		}
+	})
+
+	t.Run("A camera rejects a transform that cannot be inverted", func(t *testing.T) {
+		c := NewCamera(10, 10, math.Pi/2)
+		if err := c.SetTransform(singular); err == nil {
+			t.Errorf("Expected an error for a singular transform")
+		}
+	})
+}
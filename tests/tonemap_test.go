@@ -0,0 +1,97 @@
+package tests
+
+import (
+	. "github.com/michaelzhao820/raytracer/raytracer"
+	"math"
+	"testing"
+)
+
+func TestToneMappers(t *testing.T) {
+	t.Run("Clamping cuts channels off at 0 and 1", func(t *testing.T) {
+		got := NewClampToneMapper().Map(NewColor(-0.5, 0.5, 4))
+		assertColorEqual(t, got, NewColor(0, 0.5, 1))
+	})
+
+	t.Run("The basic Reinhard curve maps v to v/(1+v)", func(t *testing.T) {
+		got := NewReinhardToneMapper(math.Inf(1)).Map(NewColor(0, 1, 3))
+		assertColorEqual(t, got, NewColor(0, 0.5, 0.75))
+	})
+
+	t.Run("Reinhard with a white point maps white, and anything brighter, to 1", func(t *testing.T) {
+		m := NewReinhardToneMapper(4)
+		got := m.Map(NewColor(4, 10, 1))
+		assertColorEqual(t, got, NewColor(1, 1, 1.0625/2))
+	})
+
+	t.Run("Reinhard without a positive white point uses the basic curve", func(t *testing.T) {
+		for _, white := range []float64{0, -2} {
+			got := NewReinhardToneMapper(white).Map(NewColor(0, 1, 3))
+			assertColorEqual(t, got, NewColor(0, 0.5, 0.75))
+		}
+	})
+
+	t.Run("ACES keeps black black and rolls highlights off to 1", func(t *testing.T) {
+		m := NewACESToneMapper()
+		got := m.Map(NewColor(0, 0.18, 100))
+		assertColorEqual(t, got, NewColor(0, 0.2669, 1))
+		if v := m.Map(NewColor(2, 2, 2))[R]; v <= 0.8 || v >= 1 {
+			t.Errorf("Expected ACES to map 2 into (0.8, 1), got %v", v)
+		}
+	})
+
+	t.Run("Tone mappers keep the order of channel values", func(t *testing.T) {
+		mappers := []ToneMapper{NewReinhardToneMapper(math.Inf(1)), NewReinhardToneMapper(8), NewACESToneMapper()}
+		for _, m := range mappers {
+			previous := -1.0
+			for v := 0.0; v < 8; v += 0.25 {
+				got := m.Map(NewColor(v, v, v))[R]
+				if got < previous || got > 1 {
+					t.Errorf("%T: mapping %v gave %v after %v", m, v, got, previous)
+				}
+				previous = got
+			}
+		}
+	})
+}
+
+func TestCanvasToneMap(t *testing.T) {
+	c := NewCanvas(2, 1)
+	c.WritePixel(0, 0, NewColor(0.25, 0.5, 1))
+	c.WritePixel(1, 0, NewColor(3, 0, 0))
+
+	t.Run("Each stop of exposure doubles the brightness", func(t *testing.T) {
+		mapped := c.ToneMap(1, NewClampToneMapper())
+		assertColorEqual(t, mapped.PixelAt(0, 0), NewColor(0.5, 1, 1))
+		mapped = c.ToneMap(-2, NewClampToneMapper())
+		assertColorEqual(t, mapped.PixelAt(1, 0), NewColor(0.75, 0, 0))
+	})
+
+	t.Run("Tone mapping leaves the original canvas unchanged", func(t *testing.T) {
+		mapped := c.ToneMap(0, NewReinhardToneMapper(math.Inf(1)))
+		assertColorEqual(t, mapped.PixelAt(1, 0), NewColor(0.75, 0, 0))
+		assertColorEqual(t, c.PixelAt(1, 0), NewColor(3, 0, 0))
+	})
+}
+
+func TestSRGB(t *testing.T) {
+	t.Run("Encoding linear colours with the sRGB transfer function", func(t *testing.T) {
+		got := LinearToSRGB(NewColor(0.002, 0.5, 2))
+		assertColorEqual(t, got, NewColor(0.02584, 0.7354, 1))
+	})
+
+	t.Run("Decoding undoes encoding", func(t *testing.T) {
+		for _, v := range []float64{0, 0.001, 0.0031308, 0.2, 0.5, 0.9, 1} {
+			got := SRGBToLinear(LinearToSRGB(NewColor(v, v, v)))
+			assertColorEqual(t, got, NewColor(v, v, v))
+		}
+	})
+
+	t.Run("Encoding a canvas before writing it as PPM", func(t *testing.T) {
+		c := NewCanvas(1, 1)
+		c.WritePixel(0, 0, NewColor(0.5, 0.5, 0.5))
+		encoded := c.EncodeSRGB()
+		assertColorEqual(t, encoded.PixelAt(0, 0), NewColor(0.7354, 0.7354, 0.7354))
+		decoded := encoded.DecodeSRGB()
+		assertColorEqual(t, decoded.PixelAt(0, 0), NewColor(0.5, 0.5, 0.5))
+	})
+}
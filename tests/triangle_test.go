@@ -0,0 +1,74 @@
+package tests
+
+import (
+	. "github.com/michaelzhao820/raytracer/raytracer"
+	"math"
+	"testing"
+)
+
+func TestTriangle(t *testing.T) {
+	newTriangle := func() *Triangle {
+		return NewTriangle(NewPoint(0, 1, 0), NewPoint(-1, 0, 0), NewPoint(1, 0, 0))
+	}
+
+	t.Run("Finding the normal on a triangle", func(t *testing.T) {
+		tr := newTriangle()
+		expected := NewVector(0, 0, -1)
+		for _, p := range []Tuple{NewPoint(0, 0.5, 0), NewPoint(-0.5, 0.75, 0), NewPoint(0.5, 0.25, 0)} {
+			n := tr.NormalAt(p)
+			if !n.Equals(expected) {
+				t.Errorf("Normal at %v: expected %v, got %v", p, expected, n)
+			}
+		}
+	})
+
+	t.Run("Intersecting a ray parallel to the triangle", func(t *testing.T) {
+		tr := newTriangle()
+		xs := tr.Intersect(NewRay(NewPoint(0, -1, -2), NewVector(0, 1, 0)))
+		if len(xs) != 0 {
+			t.Errorf("Expected 0 intersections, got %d", len(xs))
+		}
+	})
+
+	t.Run("A ray misses the triangle's edges", func(t *testing.T) {
+		tr := newTriangle()
+		for _, origin := range []Tuple{NewPoint(1, 1, -2), NewPoint(-1, 1, -2), NewPoint(0, -1, -2)} {
+			xs := tr.Intersect(NewRay(origin, NewVector(0, 0, 1)))
+			if len(xs) != 0 {
+				t.Errorf("Expected ray from %v to miss, got %d intersections", origin, len(xs))
+			}
+		}
+	})
+
+	t.Run("A ray strikes a triangle", func(t *testing.T) {
+		tr := newTriangle()
+		xs := tr.Intersect(NewRay(NewPoint(0, 0.5, -2), NewVector(0, 0, 1)))
+		if len(xs) != 1 {
+			t.Fatalf("Expected 1 intersection, got %d", len(xs))
+		}
+		if !almostEqual(xs[0].GetTime(), 2) {
+			t.Errorf("Expected t = 2, got %v", xs[0].GetTime())
+		}
+	})
+}
+
+func TestSmoothTriangle(t *testing.T) {
+	newSmoothTriangle := func() *SmoothTriangle {
+		return NewSmoothTriangle(
+			NewPoint(0, 1, 0), NewPoint(-1, 0, 0), NewPoint(1, 0, 0),
+			NewVector(0, 1, 0), NewVector(-1, 0, 0), NewVector(1, 0, 0),
+		)
+	}
+
+	t.Run("An intersection with a smooth triangle stores u/v", func(t *testing.T) {
+		tri := newSmoothTriangle()
+		xs := tri.Intersect(NewRay(NewPoint(-0.2, 0.3, -2), NewVector(0, 0, 1)))
+		if len(xs) != 1 {
+			t.Fatalf("Expected 1 intersection, got %d", len(xs))
+		}
+		if math.Abs(xs[0].GetU()-0.45) > 1e-4 || math.Abs(xs[0].GetV()-0.25) > 1e-4 {
+			t.Errorf("Expected u = 0.45, v = 0.25, got u = %v, v = %v", xs[0].GetU(), xs[0].GetV())
+		}
+	})
+
+}